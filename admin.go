@@ -0,0 +1,511 @@
+package jsonform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/swaggest/rest/web"
+	"github.com/swaggest/usecase/status"
+)
+
+// Entry is a stored value with its identifier.
+type Entry[T any] struct {
+	ID    string `json:"id"`
+	Value T      `json:"value"`
+}
+
+// Store provides access to values managed by AdminPages.
+//
+// Get, Update and Delete should return status.NotFound error for unknown id.
+type Store[T any] interface {
+	List(ctx context.Context) ([]Entry[T], error)
+	Get(ctx context.Context, id string) (T, error)
+	Create(ctx context.Context, value T) (id string, err error)
+	Update(ctx context.Context, id string, value T) error
+	Delete(ctx context.Context, id string) error
+}
+
+// AdminPages serves list, create and edit pages with JSON endpoints for values of a store.
+type AdminPages[T any] struct {
+	// Title is a title of values, default is schema title or name.
+	//
+	// It is used for list page, create and edit pages are titled "Create {Title}" and "Edit {Title}".
+	Title string
+
+	// Columns and Sortable configure list table, see List.
+	Columns  []string
+	Sortable []string
+
+	// Page prepares page of request, for example with CSRFToken, optional.
+	Page func(w http.ResponseWriter, r *http.Request) (Page, error)
+
+	// CSRF enables anti-forgery protection of POST, PUT and DELETE endpoints, optional.
+	//
+	// Token is added to pages if Page does not provide Page.CSRFToken.
+	// Without CSRF, endpoints should be protected by a middleware of web service.
+	CSRF *CSRF
+
+	repo  *Repository
+	store Store[T]
+	name  string
+}
+
+// Admin creates CRUD pages for values of a store, form schema of T is added to repository if it is missing.
+func Admin[T any](repo *Repository, store Store[T]) *AdminPages[T] {
+	return &AdminPages[T]{
+		repo:  repo,
+		store: store,
+	}
+}
+
+// Mount adds pages and JSON endpoints to web service.
+//
+// Pages are served at prefix (list), prefix+"/new" (create) and prefix+"/{id}/edit" (edit),
+// values are deleted from list page.
+// JSON endpoints are prefix+".json" (GET list of entries, POST to create)
+// and prefix+"/{id}.json" (GET with ETag, PUT with If-Match, DELETE).
+//
+// Schema name is checked with Repository.Authorize, created and updated values are checked with
+// Repository.CheckLocked, fields removed by policies are not exposed and are kept on update, see Repository.ValueFor.
+// Repository must be mounted before.
+func (a *AdminPages[T]) Mount(s *web.Service, prefix string) error {
+	if a.repo.baseURL == "" {
+		return errors.New("repository is not mounted")
+	}
+
+	var v T
+
+	// Schema is registered without authorization, requests are authorized in handlers.
+	if err := a.repo.ensureSchema(v); err != nil {
+		return err
+	}
+
+	a.name = a.repo.Name(v)
+	fs := a.repo.SchemaByName(a.name)
+
+	if a.Title == "" {
+		a.Title = a.name
+
+		if fs.Schema.Title != nil {
+			a.Title = *fs.Schema.Title
+		}
+	}
+
+	s.Method(http.MethodGet, prefix, a.handle(a.listPage(prefix)))
+	s.Method(http.MethodGet, prefix+"/new", a.handle(a.createPage(prefix)))
+	s.Method(http.MethodGet, prefix+"/{id}/edit", a.handle(a.editPage(prefix)))
+
+	s.Method(http.MethodGet, prefix+".json", a.handle(a.list))
+	s.Method(http.MethodPost, prefix+".json", a.protect(a.handle(a.create)))
+	s.Method(http.MethodGet, prefix+"/{id}.json", a.handle(a.get))
+	s.Method(http.MethodPut, prefix+"/{id}.json", a.protect(a.handle(a.update)))
+	s.Method(http.MethodDelete, prefix+"/{id}.json", a.protect(a.handle(a.delete)))
+
+	return nil
+}
+
+// protect checks anti-forgery token if CSRF is configured.
+func (a *AdminPages[T]) protect(h http.Handler) http.Handler {
+	if a.CSRF == nil {
+		return h
+	}
+
+	return a.CSRF.Middleware(h)
+}
+
+// handle authorizes request and writes error response.
+func (a *AdminPages[T]) handle(h func(w http.ResponseWriter, r *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := a.repo.authorize(r.Context(), a.name)
+		if err == nil {
+			err = h(w, r)
+		}
+
+		if err != nil {
+			writeError(w, err)
+		}
+	})
+}
+
+func (a *AdminPages[T]) page(w http.ResponseWriter, r *http.Request) (Page, error) {
+	p := Page{}
+
+	if a.Page != nil {
+		var err error
+
+		if p, err = a.Page(w, r); err != nil {
+			return p, err
+		}
+	}
+
+	if a.CSRF != nil && p.CSRFToken == "" {
+		token, err := a.CSRF.Token(w, r)
+		if err != nil {
+			return p, err
+		}
+
+		p.CSRFToken = token
+
+		if p.CSRFHeader == "" {
+			p.CSRFHeader = a.CSRF.headerName()
+		}
+	}
+
+	return p, nil
+}
+
+// entries returns stored entries with fields removed by policies in context.
+func (a *AdminPages[T]) entries(ctx context.Context) ([]Entry[interface{}], error) {
+	entries, err := a.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Entry[interface{}], 0, len(entries))
+
+	for _, e := range entries {
+		v, err := a.repo.ValueFor(ctx, a.name, e.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, Entry[interface{}]{ID: e.ID, Value: v})
+	}
+
+	return res, nil
+}
+
+// decode reads submitted value, fields removed by policies are taken from current value,
+// locked fields must not differ from current value.
+func (a *AdminPages[T]) decode(r *http.Request, current T) (T, error) {
+	var v T
+
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+		return v, status.Wrap(fmt.Errorf("decoding value: %w", err), status.InvalidArgument)
+	}
+
+	merged, err := a.repo.withRemoved(r.Context(), a.name, current, v)
+	if err != nil {
+		return v, err
+	}
+
+	if m, ok := merged.(T); ok {
+		v = m
+	} else {
+		j, err := json.Marshal(merged)
+		if err != nil {
+			return v, err
+		}
+
+		var mv T
+
+		if err := json.Unmarshal(j, &mv); err != nil {
+			return v, err
+		}
+
+		v = mv
+	}
+
+	if err := a.repo.CheckLocked(r.Context(), a.name, current, v); err != nil {
+		return v, err
+	}
+
+	return v, nil
+}
+
+// form returns form with schema of request context and submit button.
+func (a *AdminPages[T]) form(ctx context.Context, title, submitText string) (Form, error) {
+	s, err := a.repo.SchemaFor(ctx, a.name)
+	if err != nil {
+		return Form{}, err
+	}
+
+	return Form{
+		Title:             title,
+		Schema:            s.WithSubmit(submitText),
+		OnBeforeSubmit:    "startSpinner",
+		OnRequestFinished: "stopSpinner",
+	}, nil
+}
+
+func (a *AdminPages[T]) listPage(prefix string) func(w http.ResponseWriter, r *http.Request) error {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		p, err := a.page(w, r)
+		if err != nil {
+			return err
+		}
+
+		entries, err := a.entries(r.Context())
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		return a.repo.RenderListContext(r.Context(), w, p, List{
+			Title:      a.Title,
+			SchemaName: a.name,
+			Items:      entries,
+			ValueKey:   "value",
+			Columns:    a.Columns,
+			Sortable:   a.Sortable,
+			CreateURL:  prefix + "/new",
+			EditURL:    prefix + "/{id}/edit",
+			DeleteURL:  prefix + "/{id}.json",
+		})
+	}
+}
+
+func (a *AdminPages[T]) createPage(prefix string) func(w http.ResponseWriter, r *http.Request) error {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		p, err := a.page(w, r)
+		if err != nil {
+			return err
+		}
+
+		f, err := a.form(r.Context(), "Create "+a.Title, "Create")
+		if err != nil {
+			return err
+		}
+
+		f.SubmitURL = prefix + ".json"
+		f.SubmitMethod = http.MethodPost
+		f.SuccessStatus = http.StatusCreated
+		f.SuccessRedirect = prefix
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		return a.repo.RenderContext(r.Context(), w, p, f)
+	}
+}
+
+func (a *AdminPages[T]) editPage(prefix string) func(w http.ResponseWriter, r *http.Request) error {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		p, err := a.page(w, r)
+		if err != nil {
+			return err
+		}
+
+		id := chi.URLParam(r, "id")
+
+		// Existence is checked before rendering, value is loaded by form.js with ETag.
+		if _, err := a.store.Get(r.Context(), id); err != nil {
+			return err
+		}
+
+		f, err := a.form(r.Context(), "Edit "+a.Title, "Save")
+		if err != nil {
+			return err
+		}
+
+		f.ValueURL = prefix + "/{id}.json"
+		f.SubmitURL = prefix + "/{id}.json"
+		f.URLParams = map[string]string{"id": id}
+		f.SubmitMethod = http.MethodPut
+		f.SuccessStatus = http.StatusNoContent
+		f.SuccessRedirect = prefix
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		return a.repo.RenderContext(r.Context(), w, p, f)
+	}
+}
+
+func (a *AdminPages[T]) list(w http.ResponseWriter, r *http.Request) error {
+	entries, err := a.entries(r.Context())
+	if err != nil {
+		return err
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+
+	return nil
+}
+
+func (a *AdminPages[T]) create(w http.ResponseWriter, r *http.Request) error {
+	var zero T
+
+	v, err := a.decode(r, zero)
+	if err != nil {
+		return err
+	}
+
+	id, err := a.store.Create(r.Context(), v)
+	if err != nil {
+		return err
+	}
+
+	value, err := a.repo.ValueFor(r.Context(), a.name, v)
+	if err != nil {
+		return err
+	}
+
+	writeJSON(w, http.StatusCreated, Entry[interface{}]{ID: id, Value: value})
+
+	return nil
+}
+
+func (a *AdminPages[T]) get(w http.ResponseWriter, r *http.Request) error {
+	v, err := a.store.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		return err
+	}
+
+	// ETag is of stored value, so that it matches on update.
+	etag, err := ETag(v)
+	if err != nil {
+		return err
+	}
+
+	value, err := a.repo.ValueFor(r.Context(), a.name, v)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("ETag", etag)
+	writeJSON(w, http.StatusOK, value)
+
+	return nil
+}
+
+func (a *AdminPages[T]) update(w http.ResponseWriter, r *http.Request) error {
+	id := chi.URLParam(r, "id")
+
+	current, err := a.store.Get(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if err := CheckIfMatch(r.Header.Get("If-Match"), current); err != nil {
+		return err
+	}
+
+	v, err := a.decode(r, current)
+	if err != nil {
+		return err
+	}
+
+	if err := a.store.Update(r.Context(), id, v); err != nil {
+		return err
+	}
+
+	etag, err := ETag(v)
+	if err != nil {
+		return err
+	}
+
+	// New ETag allows subsequent updates from the same form.
+	w.Header().Set("ETag", etag)
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+func (a *AdminPages[T]) delete(w http.ResponseWriter, r *http.Request) error {
+	if err := a.store.Delete(r.Context(), chi.URLParam(r, "id")); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// MemoryStore keeps values in memory, it is safe for concurrent use.
+//
+// Identifiers are sequential numbers, entries are listed in order of creation.
+type MemoryStore[T any] struct {
+	mu     sync.Mutex
+	values map[string]T
+	seq    int
+}
+
+// NewMemoryStore creates an empty store.
+func NewMemoryStore[T any]() *MemoryStore[T] {
+	return &MemoryStore[T]{values: make(map[string]T)}
+}
+
+// List returns all entries.
+func (s *MemoryStore[T]) List(_ context.Context) ([]Entry[T], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]Entry[T], 0, len(s.values))
+
+	for id, v := range s.values {
+		entries = append(entries, Entry[T]{ID: id, Value: v})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, _ := strconv.Atoi(entries[i].ID)
+		b, _ := strconv.Atoi(entries[j].ID)
+
+		return a < b
+	})
+
+	return entries, nil
+}
+
+// Get returns value by id.
+func (s *MemoryStore[T]) Get(_ context.Context, id string) (T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.values[id]
+	if !ok {
+		return v, notFound(id)
+	}
+
+	return v, nil
+}
+
+// Create adds value and returns its id.
+func (s *MemoryStore[T]) Create(_ context.Context, value T) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	id := strconv.Itoa(s.seq)
+	s.values[id] = value
+
+	return id, nil
+}
+
+// Update replaces value by id.
+func (s *MemoryStore[T]) Update(_ context.Context, id string, value T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[id]; !ok {
+		return notFound(id)
+	}
+
+	s.values[id] = value
+
+	return nil
+}
+
+// Delete removes value by id.
+func (s *MemoryStore[T]) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[id]; !ok {
+		return notFound(id)
+	}
+
+	delete(s.values, id)
+
+	return nil
+}
+
+func notFound(id string) error {
+	return status.Wrap(fmt.Errorf("entry %s not found", id), status.NotFound)
+}
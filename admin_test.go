@@ -0,0 +1,252 @@
+package jsonform_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/assertjson"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/openapi-go/openapi31"
+	"github.com/swaggest/rest/web"
+	"github.com/swaggest/usecase/status"
+)
+
+func TestAdmin(t *testing.T) {
+	s := web.NewService(openapi31.NewReflector())
+	repo := jsonform.NewRepository(s.OpenAPIReflector().JSONSchemaReflector())
+	store := jsonform.NewMemoryStore[User]()
+	admin := jsonform.Admin[User](repo, store)
+
+	require.EqualError(t, admin.Mount(s, "/users"), "repository is not mounted")
+
+	repo.Mount(s, "/json-form/")
+
+	repo.AddPolicy(func(ctx context.Context, schemaName string, item jsonform.FormItem) jsonform.FieldAccess {
+		if item.Key == "status" {
+			return jsonform.FieldReadOnly
+		}
+
+		return jsonform.FieldEditable
+	})
+
+	admin.Sortable = []string{"age"}
+	require.NoError(t, admin.Mount(s, "/users"))
+
+	serve := func(method, url, body string, header ...string) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+
+		s.ServeHTTP(rw, req)
+
+		return rw
+	}
+
+	rw := serve(http.MethodPost, "/users.json", `{"firstName":"John","lastName":"Doe","age":30}`)
+	assert.Equal(t, http.StatusCreated, rw.Code)
+	assertjson.Equal(t, []byte(`{"id":"1","value":{"firstName":"John","lastName":"Doe","locale":"","age":30,"status":"","bio":""}}`),
+		rw.Body.Bytes())
+
+	rw = serve(http.MethodGet, "/users.json", "")
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Contains(t, rw.Body.String(), `[{"id":"1","value":{"firstName":"John"`)
+
+	rw = serve(http.MethodGet, "/users/1.json", "")
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	etag := rw.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	rw = serve(http.MethodPut, "/users/1.json", `{"firstName":"Jane","lastName":"Doe","status":"active"}`, "If-Match", etag)
+	assert.Equal(t, http.StatusForbidden, rw.Code, rw.Body.String())
+
+	rw = serve(http.MethodPut, "/users/1.json", `{"firstName":"Jane","lastName":"Doe"}`, "If-Match", `"outdated"`)
+	assert.Equal(t, http.StatusPreconditionFailed, rw.Code, rw.Body.String())
+
+	rw = serve(http.MethodPut, "/users/1.json", `{"firstName":"Jane","lastName":"Doe"}`, "If-Match", etag)
+	assert.Equal(t, http.StatusNoContent, rw.Code, rw.Body.String())
+
+	// Updated value has a new ETag for the next update.
+	newETag := rw.Header().Get("ETag")
+	assert.NotEmpty(t, newETag)
+	assert.NotEqual(t, etag, newETag)
+
+	rw = serve(http.MethodGet, "/users/1.json", "")
+	assert.Equal(t, newETag, rw.Header().Get("ETag"))
+
+	u, err := store.Get(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Equal(t, "Jane", u.FirstName)
+
+	rw = serve(http.MethodGet, "/users", "")
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Contains(t, rw.Body.String(), `<title>User</title>`)
+	assert.Contains(t, rw.Body.String(), `<a href="/users/new" class="pure-button pure-button-primary">Create</a>`)
+	assert.Contains(t, rw.Body.String(), `&#34;editUrl&#34;:&#34;/users/{id}/edit&#34;,&#34;deleteUrl&#34;:&#34;/users/{id}.json&#34;`)
+	assert.Contains(t, rw.Body.String(), `&#34;value&#34;:{&#34;age&#34;:0,&#34;bio&#34;:&#34;&#34;,&#34;firstName&#34;:&#34;Jane&#34;`)
+
+	rw = serve(http.MethodGet, "/users/new", "")
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Contains(t, rw.Body.String(), `<title>Create User</title>`)
+	assert.Contains(t, rw.Body.String(), `"submitUrl":"/users.json"`)
+
+	rw = serve(http.MethodGet, "/users/1/edit", "")
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Contains(t, rw.Body.String(), `<title>Edit User</title>`)
+	assert.Contains(t, rw.Body.String(), `"valueUrl":"/users/1.json"`)
+
+	rw = serve(http.MethodGet, "/users/2/edit", "")
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+
+	rw = serve(http.MethodDelete, "/users/1.json", "")
+	assert.Equal(t, http.StatusNoContent, rw.Code)
+
+	rw = serve(http.MethodDelete, "/users/1.json", "")
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+
+	repo.Authorize = func(ctx context.Context, name string) error {
+		return status.PermissionDenied
+	}
+
+	rw = serve(http.MethodGet, "/users", "")
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+}
+
+func TestAdmin_removedFields(t *testing.T) {
+	s := web.NewService(openapi31.NewReflector())
+	repo := jsonform.NewRepository(s.OpenAPIReflector().JSONSchemaReflector())
+	store := jsonform.NewMemoryStore[User]()
+
+	repo.Mount(s, "/json-form/")
+
+	repo.AddPolicy(func(ctx context.Context, schemaName string, item jsonform.FormItem) jsonform.FieldAccess {
+		switch item.Key {
+		case "status":
+			return jsonform.FieldReadOnly
+		case "bio":
+			return jsonform.FieldRemoved
+		}
+
+		return jsonform.FieldEditable
+	})
+
+	require.NoError(t, jsonform.Admin[User](repo, store).Mount(s, "/users"))
+
+	serve := func(method, url, body string, header ...string) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+
+		s.ServeHTTP(rw, req)
+
+		return rw
+	}
+
+	// Locked and removed fields can not be set on create.
+	rw := serve(http.MethodPost, "/users.json", `{"firstName":"John","status":"active"}`)
+	assert.Equal(t, http.StatusForbidden, rw.Code, rw.Body.String())
+
+	rw = serve(http.MethodPost, "/users.json", `{"firstName":"John","bio":"injected"}`)
+	assert.Equal(t, http.StatusCreated, rw.Code, rw.Body.String())
+	assertjson.Equal(t, []byte(`{"id":"1","value":{"firstName":"John","lastName":"","locale":"","age":0,"status":""}}`),
+		rw.Body.Bytes())
+
+	u, err := store.Get(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Empty(t, u.Bio)
+
+	u.Bio = "confidential"
+	require.NoError(t, store.Update(context.Background(), "1", u))
+
+	// Removed fields are not exposed.
+	rw = serve(http.MethodGet, "/users/1.json", "")
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.NotContains(t, rw.Body.String(), "bio")
+
+	etag := rw.Header().Get("ETag")
+
+	rw = serve(http.MethodGet, "/users.json", "")
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.NotContains(t, rw.Body.String(), "confidential")
+
+	rw = serve(http.MethodGet, "/users", "")
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.NotContains(t, rw.Body.String(), "confidential")
+
+	// Removed fields are kept on update.
+	rw = serve(http.MethodPut, "/users/1.json", `{"firstName":"Jane"}`, "If-Match", etag)
+	assert.Equal(t, http.StatusNoContent, rw.Code, rw.Body.String())
+
+	u, err = store.Get(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Equal(t, "Jane", u.FirstName)
+	assert.Equal(t, "confidential", u.Bio)
+}
+
+func TestAdmin_csrf(t *testing.T) {
+	s := web.NewService(openapi31.NewReflector())
+	repo := jsonform.NewRepository(s.OpenAPIReflector().JSONSchemaReflector())
+	admin := jsonform.Admin[User](repo, jsonform.NewMemoryStore[User]())
+	admin.CSRF = jsonform.NewCSRF(jsonform.CookieCSRFStorage{})
+
+	repo.Mount(s, "/json-form/")
+	require.NoError(t, admin.Mount(s, "/users"))
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/users/new", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Contains(t, rw.Body.String(), `<meta name="csrf-token" content="`)
+
+	cookies := rw.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/users.json", strings.NewReader(`{"firstName":"John"}`)))
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/users.json", strings.NewReader(`{"firstName":"John"}`))
+	req.AddCookie(cookies[0])
+	req.Header.Set(jsonform.CSRFHeader, cookies[0].Value)
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusCreated, rw.Code, rw.Body.String())
+}
+
+func TestAdmin_Mount_authorize(t *testing.T) {
+	s := web.NewService(openapi31.NewReflector())
+	repo := jsonform.NewRepository(s.OpenAPIReflector().JSONSchemaReflector())
+	repo.Authorize = func(ctx context.Context, name string) error {
+		if ctx.Value(roleCtxKey{}) != "admin" {
+			return status.PermissionDenied
+		}
+
+		return nil
+	}
+
+	repo.Mount(s, "/json-form/")
+	require.NoError(t, jsonform.Admin[User](repo, jsonform.NewMemoryStore[User]()).Mount(s, "/users"))
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req = req.WithContext(context.WithValue(req.Context(), roleCtxKey{}, "admin"))
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusOK, rw.Code, rw.Body.String())
+	assert.Contains(t, rw.Body.String(), `<title>User</title>`)
+}
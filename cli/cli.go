@@ -0,0 +1,193 @@
+// Package cli implements commands to inspect form schemas of a repository without running a web service.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/swaggest/assertjson"
+	"github.com/swaggest/jsonform-go"
+)
+
+// Usage describes available commands.
+const Usage = `Commands:
+  names                 print names of registered schemas
+  schema [names...]     print form schemas as JSON, all schemas if names are omitted
+  diff [flags] [names...]
+                        compare form schemas with golden files
+  render [flags] name   render HTML page with form of schema
+`
+
+// Run executes command with arguments and writes result to stdout.
+//
+// Arguments start with command name, e.g. []string{"schema", "user"}.
+func Run(repo *jsonform.Repository, args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("missing command\n" + Usage)
+	}
+
+	switch args[0] {
+	case "names":
+		for _, name := range repo.Names() {
+			if _, err := fmt.Fprintln(stdout, name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case "schema":
+		return printSchemas(repo, args[1:], stdout)
+	case "diff":
+		return diff(repo, args[1:], stdout)
+	case "render":
+		return render(repo, args[1:], stdout)
+	default:
+		return fmt.Errorf("unknown command: %s\n%s", args[0], Usage)
+	}
+}
+
+// schemaNames returns all names if none are requested, or checks that requested names are registered.
+func schemaNames(repo *jsonform.Repository, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return repo.Names(), nil
+	}
+
+	for _, name := range names {
+		if repo.SchemaByName(name) == nil {
+			return nil, fmt.Errorf("unknown schema %s", name)
+		}
+	}
+
+	return names, nil
+}
+
+func printSchemas(repo *jsonform.Repository, args []string, stdout io.Writer) error {
+	names, err := schemaNames(repo, args)
+	if err != nil {
+		return err
+	}
+
+	var v interface{}
+
+	if len(args) == 1 {
+		v = repo.SchemaByName(names[0])
+	} else {
+		schemas := make(map[string]*jsonform.FormSchema, len(names))
+
+		for _, name := range names {
+			schemas[name] = repo.SchemaByName(name)
+		}
+
+		v = schemas
+	}
+
+	j, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(stdout, string(j))
+
+	return err
+}
+
+func diff(repo *jsonform.Repository, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+
+	dir := fs.String("dir", "testdata", "directory of golden files named {name}.json")
+	update := fs.Bool("update", false, "write current schemas to golden files")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	names, err := schemaNames(repo, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	if *update {
+		if err := os.MkdirAll(*dir, 0o700); err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+
+	for _, name := range names {
+		actual, err := json.MarshalIndent(repo.SchemaByName(name), "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fn := filepath.Join(*dir, name+".json")
+
+		if *update {
+			if err := os.WriteFile(fn, append(actual, '\n'), 0o600); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		expected, err := os.ReadFile(filepath.Clean(fn))
+		if err == nil {
+			err = assertjson.FailNotEqual(expected, actual)
+		}
+
+		if err != nil {
+			failed++
+
+			if _, err := fmt.Fprintf(stdout, "%s: %v\n", name, err); err != nil {
+				return err
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d schemas differ from golden files", failed, len(names))
+	}
+
+	return nil
+}
+
+func render(repo *jsonform.Repository, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+
+	form := jsonform.Form{}
+	page := jsonform.Page{}
+
+	fs.StringVar(&form.Title, "title", "", "form title")
+	fs.StringVar(&form.ValueURL, "value-url", "", "URL to fetch value")
+	fs.StringVar(&form.SubmitURL, "submit-url", "", "URL to submit form")
+	fs.StringVar(&form.SubmitMethod, "submit-method", "POST", "HTTP method to use on form submit")
+	fs.IntVar(&form.SuccessStatus, "success-status", 0, "success HTTP status code to expect on submit")
+	fs.StringVar(&form.SubmitText, "submit-text", "Submit", "submit button text")
+	fs.StringVar(&page.BaseURL, "base-url", "/json-form/", "URL prefix of static assets")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("render requires exactly one schema name")
+	}
+
+	form.SchemaName = fs.Arg(0)
+
+	s := repo.SchemaByName(form.SchemaName)
+	if s == nil {
+		return fmt.Errorf("unknown schema %s", form.SchemaName)
+	}
+
+	form.Schema = s.WithSubmit(form.SubmitText)
+
+	return repo.Render(stdout, page, form)
+}
@@ -0,0 +1,75 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/jsonform-go/cli"
+	"github.com/swaggest/jsonschema-go"
+)
+
+type contact struct {
+	Name  string `json:"name" title:"Name"`
+	Email string `json:"email" format:"email"`
+}
+
+func newRepo(t *testing.T) *jsonform.Repository {
+	t.Helper()
+
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+	require.NoError(t, repo.AddNamed(contact{}, "contact"))
+	require.NoError(t, repo.AddSchemaJSON("note", []byte(`{"type":"object","properties":{"text":{"type":"string"}}}`)))
+
+	return repo
+}
+
+func TestRun(t *testing.T) {
+	repo := newRepo(t)
+	out := bytes.NewBuffer(nil)
+
+	require.NoError(t, cli.Run(repo, []string{"names"}, out))
+	assert.Equal(t, "contact\nnote\n", out.String())
+
+	out.Reset()
+	require.NoError(t, cli.Run(repo, []string{"schema", "note"}, out))
+	assert.Contains(t, out.String(), `"key": "text"`)
+	assert.NotContains(t, out.String(), `"note"`)
+
+	out.Reset()
+	require.NoError(t, cli.Run(repo, []string{"schema"}, out))
+	assert.Contains(t, out.String(), `"note": {`)
+	assert.Contains(t, out.String(), `"contact": {`)
+
+	out.Reset()
+	require.NoError(t, cli.Run(repo, []string{"render", "-title", "Contact", "-base-url", "assets/", "contact"}, out))
+	assert.Contains(t, out.String(), `<title>Contact</title>`)
+	assert.Contains(t, out.String(), `src="assets/form.js"`)
+	assert.Contains(t, out.String(), `"type":"submit"`)
+
+	assert.EqualError(t, cli.Run(repo, []string{"schema", "unknown"}, out), "unknown schema unknown")
+	assert.EqualError(t, cli.Run(repo, []string{"render"}, out), "render requires exactly one schema name")
+	assert.Error(t, cli.Run(repo, []string{"foo"}, out))
+	assert.Error(t, cli.Run(repo, nil, out))
+}
+
+func TestRun_diff(t *testing.T) {
+	repo := newRepo(t)
+	dir := t.TempDir()
+	out := bytes.NewBuffer(nil)
+
+	require.NoError(t, cli.Run(repo, []string{"diff", "-dir", dir, "-update"}, out))
+	require.NoError(t, cli.Run(repo, []string{"diff", "-dir", dir}, out))
+	assert.Empty(t, out.String())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "note.json"), []byte(`{"schema":{},"form":[]}`), 0o600))
+	assert.EqualError(t, cli.Run(repo, []string{"diff", "-dir", dir}, out), "1 of 2 schemas differ from golden files")
+	assert.Contains(t, out.String(), "note: not equal:")
+
+	out.Reset()
+	require.NoError(t, cli.Run(repo, []string{"diff", "-dir", dir, "contact"}, out))
+}
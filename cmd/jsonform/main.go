@@ -0,0 +1,151 @@
+// Command jsonform prints form schemas and renders form pages of a jsonform.Repository.
+//
+// Repository can be loaded from a Go plugin that exports "Repository" function or variable:
+//
+//	go build -buildmode=plugin -o forms.so ./forms
+//	jsonform -plugin forms.so schema user
+//
+// Alternatively, a small main package can be generated to run commands without plugins:
+//
+//	jsonform gen -pkg example.com/app/forms -func Repository -o ./cmd/forms/main.go
+//	go run ./cmd/forms render user > user.html
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"plugin"
+	"text/template"
+
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/jsonform-go/cli"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("jsonform", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: jsonform -plugin forms.so <command> [flags] [names...]\n"+
+			"       jsonform gen -pkg <import path> [-func Repository] [-o main.go]\n\nFlags:\n")
+		fs.PrintDefaults()
+		fmt.Fprint(fs.Output(), "\n"+cli.Usage)
+	}
+
+	pluginPath := fs.String("plugin", "", "path to Go plugin with repository")
+	symbol := fs.String("symbol", "Repository", "name of function or variable that provides *jsonform.Repository in plugin")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.Arg(0) == "gen" {
+		return gen(fs.Args()[1:], stdout)
+	}
+
+	if *pluginPath == "" {
+		fs.Usage()
+
+		return errors.New("missing -plugin")
+	}
+
+	repo, err := loadPlugin(*pluginPath, *symbol)
+	if err != nil {
+		return err
+	}
+
+	return cli.Run(repo, fs.Args(), stdout)
+}
+
+func loadPlugin(path, symbol string) (*jsonform.Repository, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := p.Lookup(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := s.(type) {
+	case func() *jsonform.Repository:
+		return v(), nil
+	case **jsonform.Repository:
+		return *v, nil
+	default:
+		return nil, fmt.Errorf("%s must be func() *jsonform.Repository or *jsonform.Repository variable, %T found", symbol, s)
+	}
+}
+
+var mainTemplate = template.Must(template.New("main").Parse(`// Code generated by jsonform gen. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	forms {{printf "%q" .Package}}
+	"github.com/swaggest/jsonform-go/cli"
+)
+
+func main() {
+	if err := cli.Run(forms.{{.Func}}(), os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`))
+
+func gen(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+
+	pkg := fs.String("pkg", "", "import path of package with repository function")
+	fn := fs.String("func", "Repository", "name of function that returns *jsonform.Repository")
+	out := fs.String("o", "", "output file, default stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *pkg == "" {
+		return errors.New("missing -pkg")
+	}
+
+	if !token.IsExported(*fn) || !token.IsIdentifier(*fn) {
+		return fmt.Errorf("invalid function name: %s", *fn)
+	}
+
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			if err := f.Close(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}()
+
+		stdout = f
+	}
+
+	return mainTemplate.Execute(stdout, struct {
+		Package string
+		Func    string
+	}{
+		Package: *pkg,
+		Func:    *fn,
+	})
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_gen(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	require.NoError(t, run([]string{"gen", "-pkg", "example.com/app/forms", "-func", "Forms"}, out))
+	assert.Contains(t, out.String(), `forms "example.com/app/forms"`)
+	assert.Contains(t, out.String(), `cli.Run(forms.Forms(), os.Args[1:], os.Stdout)`)
+
+	formatted, err := format.Source(out.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, out.String(), string(formatted))
+
+	assert.EqualError(t, run([]string{"gen"}, out), "missing -pkg")
+	assert.EqualError(t, run([]string{"gen", "-pkg", "example.com/app/forms", "-func", "repo"}, out),
+		"invalid function name: repo")
+	assert.EqualError(t, run(nil, out), "missing -plugin")
+}
+
+func TestRun_generatedCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds generated command")
+	}
+
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not available")
+	}
+
+	root, err := filepath.Abs("../..")
+	require.NoError(t, err)
+
+	goSum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	require.NoError(t, err)
+
+	forms, err := os.ReadFile(filepath.Join("testdata", "forms", "forms.go"))
+	require.NoError(t, err)
+
+	// Generated command is built in a temporary module that replaces this module with local sources.
+	dir := t.TempDir()
+	goMod := "module example.com/gen\n\ngo 1.18\n\nrequire github.com/swaggest/jsonform-go v0.0.0\n\n" +
+		"replace github.com/swaggest/jsonform-go => " + filepath.ToSlash(root) + "\n"
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.sum"), goSum, 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "forms"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "forms", "forms.go"), forms, 0o600))
+
+	require.NoError(t, run([]string{
+		"gen", "-pkg", "example.com/gen/forms",
+		"-o", filepath.Join(dir, "main.go"),
+	}, nil))
+
+	cmd := exec.Command(goBin, "run", ".", "names")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	res, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(res))
+	assert.Equal(t, "contact\n", string(res))
+
+	cmd = exec.Command(goBin, "run", ".", "render", "-title", "Contact", "contact")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	res, err = cmd.CombinedOutput()
+	require.NoError(t, err, string(res))
+	assert.Contains(t, string(res), `<title>Contact</title>`)
+	assert.Contains(t, string(res), `{"type":"submit","title":"Submit"}`)
+}
+
+func TestRun_plugin(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+
+	err := run([]string{"-plugin", filepath.Join(t.TempDir(), "missing.so"), "names"}, out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing.so")
+
+	if testing.Short() {
+		t.Skip("builds plugin")
+	}
+
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not available")
+	}
+
+	so := filepath.Join(t.TempDir(), "forms.so")
+
+	res, err := exec.Command(goBin, "build", "-buildmode=plugin", "-o", so, "./testdata/plugin").CombinedOutput()
+	if err != nil {
+		t.Skip("plugin is not supported:", string(res))
+	}
+
+	require.NoError(t, run([]string{"-plugin", so, "names"}, out))
+	assert.Equal(t, "contact\n", out.String())
+
+	out.Reset()
+	require.NoError(t, run([]string{"-plugin", so, "-symbol", "Forms", "schema", "contact"}, out))
+	assert.Contains(t, out.String(), `"key": "email"`)
+
+	assert.EqualError(t, run([]string{"-plugin", so, "-symbol", "Invalid", "names"}, out),
+		"Invalid must be func() *jsonform.Repository or *jsonform.Repository variable, *string found")
+}
@@ -0,0 +1,23 @@
+// Package forms provides a repository for tests of generated command.
+package forms
+
+import (
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/jsonschema-go"
+)
+
+type contact struct {
+	Name  string `json:"name" title:"Name"`
+	Email string `json:"email" format:"email"`
+}
+
+// Repository returns repository with contact schema.
+func Repository() *jsonform.Repository {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	if err := repo.AddNamed(contact{}, "contact"); err != nil {
+		panic(err)
+	}
+
+	return repo
+}
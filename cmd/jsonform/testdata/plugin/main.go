@@ -0,0 +1,20 @@
+// Package main is a plugin for tests of jsonform command.
+package main
+
+import (
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/jsonform-go/cmd/jsonform/testdata/forms"
+)
+
+// Repository is loaded by jsonform command.
+func Repository() *jsonform.Repository {
+	return forms.Repository()
+}
+
+// Forms is loaded by jsonform command with -symbol.
+var Forms = forms.Repository()
+
+// Invalid has unexpected type.
+var Invalid = "invalid"
+
+func main() {}
@@ -0,0 +1,272 @@
+package jsonform
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/swaggest/usecase/status"
+)
+
+const (
+	// CSRFHeader is a default name of request header with anti-forgery token.
+	CSRFHeader = "X-CSRF-Token"
+
+	// CSRFCookie is a default name of cookie with anti-forgery token.
+	CSRFCookie = "jsonform_csrf"
+
+	// DefaultCSRFTokenTTL is a default lifetime of SessionCSRFStorage token.
+	DefaultCSRFTokenTTL = 24 * time.Hour
+)
+
+// CSRFStorage issues and checks anti-forgery tokens.
+type CSRFStorage interface {
+	// Token returns token for the request, new token is issued if needed.
+	Token(w http.ResponseWriter, r *http.Request) (string, error)
+
+	// Check returns true if token is valid for the request.
+	Check(r *http.Request, token string) bool
+}
+
+// CookieCSRFStorage implements double-submit cookie pattern.
+//
+// Token is stored in a cookie that is readable by form.js, submitted token must match cookie value.
+type CookieCSRFStorage struct {
+	// CookieName is a name of cookie, default CSRFCookie.
+	CookieName string
+
+	// Path is a cookie path, default "/".
+	Path string
+}
+
+func (c CookieCSRFStorage) cookieName() string {
+	if c.CookieName == "" {
+		return CSRFCookie
+	}
+
+	return c.CookieName
+}
+
+// Token returns token from cookie or issues a new one.
+func (c CookieCSRFStorage) Token(w http.ResponseWriter, r *http.Request) (string, error) {
+	if cookie, err := r.Cookie(c.cookieName()); err == nil && cookie.Value != "" {
+		return cookie.Value, nil
+	}
+
+	token, err := newCSRFToken()
+	if err != nil {
+		return "", err
+	}
+
+	p := c.Path
+	if p == "" {
+		p = "/"
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     c.cookieName(),
+		Value:    token,
+		Path:     p,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	return token, nil
+}
+
+// Check returns true if token matches cookie value.
+func (c CookieCSRFStorage) Check(r *http.Request, token string) bool {
+	cookie, err := r.Cookie(c.cookieName())
+	if err != nil {
+		return false
+	}
+
+	return tokensEqual(cookie.Value, token)
+}
+
+// SessionCSRFStorage keeps tokens in memory on server side, a token per session.
+//
+// Tokens expire after TTL, expired tokens are evicted when new tokens are issued.
+type SessionCSRFStorage struct {
+	// TTL is a lifetime of a token, default DefaultCSRFTokenTTL.
+	TTL time.Duration
+
+	sessionID func(r *http.Request) string
+
+	mu        sync.Mutex
+	tokens    map[string]sessionToken
+	lastEvict time.Time
+}
+
+type sessionToken struct {
+	token   string
+	expires time.Time
+}
+
+// NewSessionCSRFStorage creates server-side token storage.
+//
+// Session ID is retrieved from request with provided function, empty session ID means no session.
+func NewSessionCSRFStorage(sessionID func(r *http.Request) string) *SessionCSRFStorage {
+	return &SessionCSRFStorage{
+		sessionID: sessionID,
+		tokens:    make(map[string]sessionToken),
+	}
+}
+
+func (s *SessionCSRFStorage) ttl() time.Duration {
+	if s.TTL == 0 {
+		return DefaultCSRFTokenTTL
+	}
+
+	return s.TTL
+}
+
+// Token returns token of request session, a new token is issued if needed.
+func (s *SessionCSRFStorage) Token(_ http.ResponseWriter, r *http.Request) (string, error) {
+	sid := s.sessionID(r)
+	if sid == "" {
+		return "", errors.New("missing session")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if t, ok := s.tokens[sid]; ok && now.Before(t.expires) {
+		return t.token, nil
+	}
+
+	token, err := newCSRFToken()
+	if err != nil {
+		return "", err
+	}
+
+	s.evict(now)
+	s.tokens[sid] = sessionToken{token: token, expires: now.Add(s.ttl())}
+
+	return token, nil
+}
+
+// evict removes expired tokens, full scan is done at most once per a tenth of TTL.
+func (s *SessionCSRFStorage) evict(now time.Time) {
+	if now.Sub(s.lastEvict) < s.ttl()/10 {
+		return
+	}
+
+	s.lastEvict = now
+
+	for sid, t := range s.tokens {
+		if !now.Before(t.expires) {
+			delete(s.tokens, sid)
+		}
+	}
+}
+
+// Check returns true if token matches the one issued for request session and is not expired.
+func (s *SessionCSRFStorage) Check(r *http.Request, token string) bool {
+	sid := s.sessionID(r)
+	if sid == "" {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tokens[sid]
+	if !ok || !time.Now().Before(t.expires) {
+		return false
+	}
+
+	return tokensEqual(t.token, token)
+}
+
+// Forget removes token of a session, for example on logout.
+func (s *SessionCSRFStorage) Forget(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tokens, sessionID)
+}
+
+// Len returns number of stored tokens, including expired ones that are not evicted yet.
+func (s *SessionCSRFStorage) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.tokens)
+}
+
+// CSRF protects form submissions from cross-site request forgery.
+//
+// Token should be obtained with CSRF.Token and provided to Render with Page.CSRFToken,
+// form.js sends it in request header that is checked by CSRF.Middleware,
+// token is only sent to URLs of page origin or origins trusted with JSONForm.setTrustedOrigins.
+type CSRF struct {
+	// Storage issues and checks tokens.
+	Storage CSRFStorage
+
+	// HeaderName is a name of request header with token, default CSRFHeader.
+	HeaderName string
+}
+
+// NewCSRF creates anti-forgery protection with token storage.
+func NewCSRF(storage CSRFStorage) *CSRF {
+	return &CSRF{Storage: storage}
+}
+
+func (c *CSRF) headerName() string {
+	if c.HeaderName == "" {
+		return CSRFHeader
+	}
+
+	return c.HeaderName
+}
+
+// Token returns anti-forgery token for the request.
+func (c *CSRF) Token(w http.ResponseWriter, r *http.Request) (string, error) {
+	return c.Storage.Token(w, r)
+}
+
+// Middleware rejects unsafe requests that do not have valid token in request header.
+func (c *CSRF) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		token := r.Header.Get(c.headerName())
+		if token != "" && c.Storage.Check(r, token) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		writeError(w, status.Wrap(errors.New("missing or invalid CSRF token"), status.PermissionDenied))
+	})
+}
+
+func newCSRFToken() (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func tokensEqual(expected, received string) bool {
+	if expected == "" || received == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(received)) == 1
+}
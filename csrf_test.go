@@ -0,0 +1,149 @@
+package jsonform_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/jsonschema-go"
+)
+
+func TestCSRF_Middleware_cookie(t *testing.T) {
+	csrf := jsonform.NewCSRF(jsonform.CookieCSRFStorage{})
+
+	h := csrf.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/form", nil)
+	rw := httptest.NewRecorder()
+
+	token, err := csrf.Token(rw, req)
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	cookies := rw.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, jsonform.CSRFCookie, cookies[0].Name)
+
+	// Safe methods are not checked.
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusNoContent, rw.Code)
+
+	// Missing token.
+	req = httptest.NewRequest(http.MethodPost, "/users", nil)
+	req.AddCookie(cookies[0])
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+	assert.Equal(t, `{"status":"PERMISSION_DENIED","error":"permission denied: missing or invalid CSRF token"}`, rw.Body.String())
+
+	// Token mismatch.
+	req.Header.Set(jsonform.CSRFHeader, "foo")
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+
+	// Valid token.
+	req.Header.Set(jsonform.CSRFHeader, token)
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusNoContent, rw.Code)
+}
+
+func TestRepository_Render_csrfCookie(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.Render(buf, jsonform.Page{CSRFCookie: "xsrf"}, jsonform.Form{Value: User{}}))
+
+	assert.Contains(t, buf.String(), `<meta name="csrf-cookie" content="xsrf">`)
+	assert.Contains(t, buf.String(), `<meta name="csrf-header" content="X-CSRF-Token">`)
+	assert.NotContains(t, buf.String(), `<meta name="csrf-token"`)
+}
+
+func TestCSRF_Middleware_session(t *testing.T) {
+	storage := jsonform.NewSessionCSRFStorage(func(r *http.Request) string {
+		return r.Header.Get("X-Session")
+	})
+	csrf := jsonform.NewCSRF(storage)
+	csrf.HeaderName = "X-Token"
+
+	h := csrf.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/form", nil)
+
+	_, err := csrf.Token(httptest.NewRecorder(), req)
+	require.EqualError(t, err, "missing session")
+
+	req.Header.Set("X-Session", "abc")
+
+	token, err := csrf.Token(httptest.NewRecorder(), req)
+	require.NoError(t, err)
+
+	token2, err := csrf.Token(httptest.NewRecorder(), req)
+	require.NoError(t, err)
+	assert.Equal(t, token, token2)
+
+	req = httptest.NewRequest(http.MethodPut, "/user/1.json", nil)
+	req.Header.Set("X-Session", "def")
+	req.Header.Set("X-Token", token)
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+
+	req.Header.Set("X-Session", "abc")
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusNoContent, rw.Code)
+
+	storage.Forget("abc")
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+}
+
+func TestSessionCSRFStorage_TTL(t *testing.T) {
+	storage := jsonform.NewSessionCSRFStorage(func(r *http.Request) string {
+		return r.Header.Get("X-Session")
+	})
+	storage.TTL = 10 * time.Millisecond
+
+	req := httptest.NewRequest(http.MethodGet, "/form", nil)
+	req.Header.Set("X-Session", "abc")
+
+	token, err := storage.Token(nil, req)
+	require.NoError(t, err)
+	assert.True(t, storage.Check(req, token))
+
+	time.Sleep(20 * time.Millisecond)
+
+	assert.False(t, storage.Check(req, token))
+
+	// Issuing a token for another session evicts expired ones.
+	req2 := httptest.NewRequest(http.MethodGet, "/form", nil)
+	req2.Header.Set("X-Session", "def")
+
+	_, err = storage.Token(nil, req2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, storage.Len())
+
+	token2, err := storage.Token(nil, req)
+	require.NoError(t, err)
+	assert.NotEqual(t, token, token2)
+	assert.True(t, storage.Check(req, token2))
+}
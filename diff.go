@@ -0,0 +1,288 @@
+package jsonform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+)
+
+// ChangeKind describes how field value was changed.
+type ChangeKind string
+
+// Change kinds.
+const (
+	ChangeAdded    = ChangeKind("added")
+	ChangeRemoved  = ChangeKind("removed")
+	ChangeModified = ChangeKind("modified")
+)
+
+// Change is a difference of field values.
+type Change struct {
+	// Key is a form item key with array positions, e.g. "neighbors[1].age".
+	Key string `json:"key"`
+
+	// Title is a path of titles, e.g. "Neighbors / #2 / Age".
+	Title string `json:"title"`
+
+	Kind ChangeKind `json:"kind"`
+
+	// Before and After are formatted values, enum values are shown with titles of FormItem.TitleMap.
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+var diffTemplate = loadTemplate("diff_tmpl.html")
+
+// Diff returns changed, added and removed fields of two values of the same type.
+//
+// Array items are compared by position, fields of items that only exist in one of values
+// are reported as added or removed, fields with zero values (empty string, 0, false, etc.) are not reported then,
+// an item with only zero values is reported as a whole, e.g. "neighbors[1]".
+func (r *Repository) Diff(ctx context.Context, before, after interface{}) ([]Change, error) {
+	_, changes, err := r.diff(ctx, before, after)
+
+	return changes, err
+}
+
+func (r *Repository) diff(ctx context.Context, before, after interface{}) (*FormSchema, []Change, error) {
+	if reflect.TypeOf(before) != reflect.TypeOf(after) {
+		return nil, nil, fmt.Errorf("values must be of the same type, %T and %T received", before, after)
+	}
+
+	if before == nil {
+		return nil, nil, errors.New("values must not be nil")
+	}
+
+	fs, err := r.formSchema(ctx, before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b, err := jsonDocument(before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	a, err := jsonDocument(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d := differ{schema: &fs.Schema, before: b, after: a}
+	d.diff(fs.Form, nil, nil)
+
+	return fs, d.changes, nil
+}
+
+// RenderDiff renders changes between two values of the same type as web page.
+//
+// Repository.Authorize is not checked, field policies are applied as for an anonymous caller.
+func (r *Repository) RenderDiff(w io.Writer, p Page, before, after interface{}) error {
+	return r.RenderDiffContext(serverSide(), w, p, before, after)
+}
+
+// RenderDiffContext renders changes between two values with field policies applied in context,
+// changes of hidden and removed fields are not shown, see Repository.SchemaFor.
+//
+// Page title defaults to "Changes of {schema title}".
+func (r *Repository) RenderDiffContext(ctx context.Context, w io.Writer, p Page, before, after interface{}) error {
+	fs, changes, err := r.diff(ctx, before, after)
+	if err != nil {
+		return err
+	}
+
+	d := struct {
+		Page
+		Changes []Change
+		BaseURL string
+	}{
+		Page:    p,
+		Changes: changes,
+		BaseURL: r.baseURL,
+	}
+
+	if p.BaseURL != "" {
+		d.BaseURL = p.BaseURL
+	}
+
+	if d.Title == "" {
+		d.Title = "Changes"
+
+		if fs.Schema.Title != nil {
+			d.Title = "Changes of " + *fs.Schema.Title
+		}
+	}
+
+	return diffTemplate.Execute(w, d)
+}
+
+func jsonDocument(v interface{}) (interface{}, error) {
+	j, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeJSONNumbers(j)
+}
+
+type differ struct {
+	schema        *jsonschema.Schema
+	before, after interface{}
+	changes       []Change
+}
+
+// diff compares values of form items, index holds positions of enclosing array items.
+func (d *differ) diff(items []FormItem, index []int, titles []string) {
+	for _, fi := range items {
+		switch {
+		case fi.FormType == "hidden" || fi.FormType == "submit" || fi.FormType == "button" ||
+			fi.FormType == "actions" || fi.FormType == "help":
+			continue
+		case fi.Key == "":
+			t := titles
+
+			if fi.FormTitle != "" {
+				t = append(titles[:len(titles):len(titles)], fi.FormTitle)
+			}
+
+			d.diff(fi.Items, index, t)
+
+			continue
+		}
+
+		t := append(append(titles[:len(titles):len(titles)], parentTitles(d.schema, fi.Key)...), itemTitle(d.schema, fi))
+		bv, bok := valueAtIndex(d.before, fi.Key, index)
+		av, aok := valueAtIndex(d.after, fi.Key, index)
+
+		if fi.FormType == "array" {
+			ba, _ := bv.([]interface{})
+			aa, _ := av.([]interface{})
+
+			for i := 0; i < len(ba) || i < len(aa); i++ {
+				n := len(d.changes)
+				it := append(t[:len(t):len(t)], "#"+strconv.Itoa(i+1))
+
+				d.diff(fi.Items, append(index[:len(index):len(index)], i), it)
+
+				// Added or removed item with only zero values is reported as a whole.
+				if len(d.changes) == n && (i >= len(ba) || i >= len(aa)) {
+					c := Change{
+						Key:   indexedKey(fi.Key, index) + "[" + strconv.Itoa(i) + "]",
+						Title: strings.Join(it, " / "),
+						Kind:  ChangeAdded,
+					}
+
+					if i >= len(aa) {
+						c.Kind = ChangeRemoved
+					}
+
+					d.changes = append(d.changes, c)
+				}
+			}
+
+			continue
+		}
+
+		bok = bok && bv != nil
+		aok = aok && av != nil
+
+		c := Change{
+			Key:   indexedKey(fi.Key, index),
+			Title: strings.Join(t, " / "),
+		}
+
+		switch {
+		case bok && aok:
+			if jsonEqual(bv, av) {
+				continue
+			}
+
+			c.Kind = ChangeModified
+		case aok:
+			if isZeroJSON(av) {
+				continue
+			}
+
+			c.Kind = ChangeAdded
+		case bok:
+			if isZeroJSON(bv) {
+				continue
+			}
+
+			c.Kind = ChangeRemoved
+		default:
+			continue
+		}
+
+		if bok {
+			c.Before = formatValue(bv, fi.TitleMap)
+		}
+
+		if aok {
+			c.After = formatValue(av, fi.TitleMap)
+		}
+
+		d.changes = append(d.changes, c)
+	}
+}
+
+// isZeroJSON returns true for empty string, zero number, false, empty array and empty object.
+func isZeroJSON(v interface{}) bool {
+	switch vv := v.(type) {
+	case string:
+		return vv == ""
+	case bool:
+		return !vv
+	case json.Number:
+		f, err := vv.Float64()
+
+		return err == nil && f == 0
+	case []interface{}:
+		return len(vv) == 0
+	case map[string]interface{}:
+		return len(vv) == 0
+	}
+
+	return false
+}
+
+// indexedKey replaces "[]" in form item key with positions of index.
+func indexedKey(key string, index []int) string {
+	for _, i := range index {
+		key = strings.Replace(key, "[]", "["+strconv.Itoa(i)+"]", 1)
+	}
+
+	return key
+}
+
+// parentTitles returns titles of objects that contain a value of form item key within its closest array item,
+// e.g. ["User"] for "user.firstName".
+func parentTitles(schema *jsonschema.Schema, key string) []string {
+	prefix, rest := "", key
+
+	if p := strings.LastIndex(key, "[]."); p != -1 {
+		prefix, rest = key[:p+3], key[p+3:]
+	}
+
+	parts := strings.Split(rest, ".")
+	titles := make([]string, 0, len(parts)-1)
+
+	for i := 0; i < len(parts)-1; i++ {
+		title := parts[i]
+
+		if s := propertySchema(schema, prefix+strings.Join(parts[:i+1], ".")); s != nil && s.Title != nil {
+			title = *s.Title
+		}
+
+		titles = append(titles, title)
+	}
+
+	return titles
+}
@@ -0,0 +1,101 @@
+package jsonform_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/jsonschema-go"
+)
+
+func TestRepository_Diff(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	before := UserWithNeighbors{
+		User: User{FirstName: "John", LastName: "Doe", Age: 30, Bio: "Hi!"},
+		Neighbors: []User{
+			{FirstName: "Jane", LastName: "Roe", Age: 25},
+			{FirstName: "Jim", LastName: "Poe"},
+		},
+	}
+
+	after := before
+	after.User.FirstName = "Johnny"
+	after.User.Status = "active"
+	after.Neighbors = []User{{FirstName: "Jane", LastName: "Roe", Age: 26}}
+
+	changes, err := repo.Diff(context.Background(), before, after)
+	require.NoError(t, err)
+
+	assert.Equal(t, []jsonform.Change{
+		{Key: "user.firstName", Title: "User / First name", Kind: jsonform.ChangeModified, Before: "John", After: "Johnny"},
+		{Key: "user.status", Title: "User / Status", Kind: jsonform.ChangeModified, Before: "", After: "active"},
+		{Key: "neighbors[0].age", Title: "Neighbors / #1 / Age", Kind: jsonform.ChangeModified, Before: "25", After: "26"},
+		{Key: "neighbors[1].firstName", Title: "Neighbors / #2 / First name", Kind: jsonform.ChangeRemoved, Before: "Jim"},
+		{Key: "neighbors[1].lastName", Title: "Neighbors / #2 / Last name", Kind: jsonform.ChangeRemoved, Before: "Poe"},
+	}, changes)
+
+	// Zero values of added array items are not reported.
+	changes, err = repo.Diff(context.Background(), after, before)
+	require.NoError(t, err)
+	assert.Equal(t, []jsonform.Change{
+		{Key: "user.firstName", Title: "User / First name", Kind: jsonform.ChangeModified, Before: "Johnny", After: "John"},
+		{Key: "user.status", Title: "User / Status", Kind: jsonform.ChangeModified, Before: "active", After: ""},
+		{Key: "neighbors[0].age", Title: "Neighbors / #1 / Age", Kind: jsonform.ChangeModified, Before: "26", After: "25"},
+		{Key: "neighbors[1].firstName", Title: "Neighbors / #2 / First name", Kind: jsonform.ChangeAdded, After: "Jim"},
+		{Key: "neighbors[1].lastName", Title: "Neighbors / #2 / Last name", Kind: jsonform.ChangeAdded, After: "Poe"},
+	}, changes)
+
+	// Added and removed items with only zero values are reported as a whole.
+	withEmpty := before
+	withEmpty.Neighbors = append(before.Neighbors[:2:2], User{})
+
+	changes, err = repo.Diff(context.Background(), before, withEmpty)
+	require.NoError(t, err)
+	assert.Equal(t, []jsonform.Change{
+		{Key: "neighbors[2]", Title: "Neighbors / #3", Kind: jsonform.ChangeAdded},
+	}, changes)
+
+	changes, err = repo.Diff(context.Background(), withEmpty, before)
+	require.NoError(t, err)
+	assert.Equal(t, []jsonform.Change{
+		{Key: "neighbors[2]", Title: "Neighbors / #3", Kind: jsonform.ChangeRemoved},
+	}, changes)
+
+	_, err = repo.Diff(context.Background(), before, User{})
+	assert.EqualError(t, err, "values must be of the same type, jsonform_test.UserWithNeighbors and jsonform_test.User received")
+}
+
+func TestRepository_RenderDiff(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.RenderDiff(buf, jsonform.Page{}, User{FirstName: "John"}, User{FirstName: "Jane", Bio: "Hi!"}))
+
+	html := spaces.ReplaceAllString(buf.String(), " ")
+	assert.Contains(t, html, `<title>Changes of User</title>`)
+	assert.Contains(t, html, `<tr class="warning jsonform-modified" data-key="firstName"> <td>First name</td>`+
+		` <td><del>John</del></td> <td><ins>Jane</ins></td> </tr>`)
+	assert.Contains(t, html, `<td><del>John</del></td>`)
+
+	buf.Reset()
+	require.NoError(t, repo.RenderDiff(buf, jsonform.Page{}, User{FirstName: "John"}, User{FirstName: "John"}))
+	assert.Contains(t, buf.String(), `<p>No changes.</p>`)
+
+	buf.Reset()
+	require.NoError(t, repo.RenderDiff(buf, jsonform.Page{
+		Title:          "Audit",
+		BaseURL:        "/assets/",
+		AppendHTMLHead: `<link rel="stylesheet" href="/custom.css">`,
+		PrependHTML:    `<nav>Menu</nav>`,
+	}, User{FirstName: "John"}, User{FirstName: "Jane"}))
+
+	html = buf.String()
+	assert.Contains(t, html, `<title>Audit</title>`)
+	assert.Contains(t, html, `href="/assets/pure.css"`)
+	assert.Contains(t, html, `<link rel="stylesheet" href="/custom.css">`)
+	assert.Contains(t, html, `<nav>Menu</nav>`)
+}
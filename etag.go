@@ -0,0 +1,49 @@
+package jsonform
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/swaggest/usecase/status"
+)
+
+// ETag returns strong entity tag of value's JSON representation, for example `"2c26b46b68ffc68f"`.
+//
+// Value handlers can send it in ETag response header to enable conflict detection in form.js,
+// which sends it back in If-Match header on submit, see CheckIfMatch.
+func ETag(value interface{}) (string, error) {
+	j, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.Sum256(j)
+
+	return `"` + hex.EncodeToString(h[:8]) + `"`, nil
+}
+
+// CheckIfMatch returns status.FailedPrecondition error if If-Match header value does not match ETag of current value.
+//
+// Empty header value is accepted to allow unconditional updates.
+func CheckIfMatch(ifMatch string, current interface{}) error {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" || ifMatch == "*" {
+		return nil
+	}
+
+	etag, err := ETag(current)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(t) == etag {
+			return nil
+		}
+	}
+
+	return status.Wrap(errors.New("value was changed, ETag does not match If-Match"), status.FailedPrecondition)
+}
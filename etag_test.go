@@ -0,0 +1,32 @@
+package jsonform_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/rest"
+)
+
+func TestCheckIfMatch(t *testing.T) {
+	u := User{FirstName: "John"}
+
+	etag, err := jsonform.ETag(u)
+	require.NoError(t, err)
+	assert.Len(t, etag, 18)
+
+	require.NoError(t, jsonform.CheckIfMatch(etag, u))
+	require.NoError(t, jsonform.CheckIfMatch(`"abc", `+etag, u))
+	require.NoError(t, jsonform.CheckIfMatch("", u))
+	require.NoError(t, jsonform.CheckIfMatch("*", u))
+
+	err = jsonform.CheckIfMatch(etag, User{FirstName: "Jane"})
+	assert.EqualError(t, err, "failed precondition: value was changed, ETag does not match If-Match")
+
+	code, _ := rest.Err(err)
+	assert.Equal(t, http.StatusPreconditionFailed, code)
+
+	require.Error(t, jsonform.CheckIfMatch("W/"+etag, u))
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 
@@ -30,13 +31,12 @@ func main() {
 
 	jf := jsonform.NewRepository(s.OpenAPIReflector().JSONSchemaReflector())
 	_ = jf.Add(User{})
-	ur.schemaName = jf.Name(User{})
 
 	// Add use case handler to router.
 	s.Post("/users", createUser(ur), nethttp.SuccessStatus(http.StatusCreated))
 	s.Get("/users.json", listUsers(ur))
 	s.Get("/user/{id}.json", getUser(ur))
-	s.Put("/user/{id}.json", updateUser(ur))
+	s.Put("/user/{id}.json", updateUser(ur), nethttp.SuccessStatus(http.StatusNoContent))
 
 	// Static forms.
 	s.Get("/create-user", createUserForm(jf))
@@ -46,7 +46,24 @@ func main() {
 	s.Docs("/docs", swgui.New)
 
 	jf.Mount(s, "/json-form/")
-	s.Method(http.MethodGet, "/", ur)
+
+	// Forms for all operations at /json-form/operations.html.
+	if err := jf.MountOperations(s); err != nil {
+		log.Fatal(err)
+	}
+
+	// CRUD pages for users kept in memory at /admin/users.
+	us := jsonform.NewMemoryStore[User]()
+	_, _ = us.Create(context.Background(), ur.list()[0])
+
+	admin := jsonform.Admin[User](jf, us)
+	admin.CSRF = jsonform.NewCSRF(jsonform.CookieCSRFStorage{})
+
+	if err := admin.Mount(s, "/admin/users"); err != nil {
+		log.Fatal(err)
+	}
+
+	s.Get("/", listUsersPage(jf, ur))
 
 	// Start server.
 	log.Println("JSON Forms at http://localhost:8011/, SwaggerUI docs at http://localhost:8011/docs")
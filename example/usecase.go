@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"html/template"
 	"log"
 	"net/http"
 	"strconv"
@@ -61,22 +62,79 @@ setup()
 	return u
 }
 
+func listUsersPage(r *jsonform.Repository, ur *userRepo) usecase.Interactor {
+	type userEntry struct {
+		ID   int  `json:"id"`
+		User User `json:"user"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, input struct{}, output *usecase.OutputWithEmbeddedWriter) error {
+		users := ur.list()
+		entries := make([]userEntry, 0, len(users))
+		links := "\n<ul>\n"
+
+		for i, u := range users {
+			entries = append(entries, userEntry{ID: i + 1, User: u})
+
+			id := strconv.Itoa(i + 1)
+			links += `<li>` + template.HTMLEscapeString(u.FirstName+" "+u.LastName) + `
+<a href="/json-form/form.html?title=Edit%20user&amp;schemaName=` + r.Name(User{}) + `&amp;valueUrl=/user/` + id +
+				`.json&amp;submitUrl=/user/` + id + `.json&amp;submitMethod=PUT&amp;successStatus=204">Edit with dynamic form</a>
+</li>
+`
+		}
+
+		links += "</ul>\n"
+
+		return r.RenderList(output.Writer, jsonform.Page{
+			Title: "Users",
+			PrependHTML: template.HTML(`
+<div style="margin:2em">
+<a href="/json-form/form.html?title=Create%20user&amp;schemaName=` + r.Name(User{}) + `&amp;submitUrl=/users&amp;submitMethod=POST&amp;successStatus=201">Create user with dynamic form</a>
+<br />
+<a href="/json-form/operations.html">All operations</a>
+<br />
+<a href="/admin/users">Users admin</a>
+</div>`),
+			AppendHTML: template.HTML(`<div style="margin:2em">` + links + `</div>`),
+		}, jsonform.List{
+			Title:      "Users",
+			SchemaName: r.Name(User{}),
+			Items:      entries,
+			ValueKey:   "user",
+			Columns:    []string{"firstName", "lastName", "age", "status"},
+			Sortable:   []string{"firstName", "lastName", "age"},
+			CreateURL:  "/create-user",
+			EditURL:    "/edit-user/{id}",
+		})
+	})
+
+	return u
+}
+
 func editUserForm(r *jsonform.Repository, ur *userRepo) usecase.Interactor {
 	type in struct {
 		ID int `path:"id"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, input in, output *usecase.OutputWithEmbeddedWriter) error {
-		user, err := ur.get(input.ID)
+		if _, err := ur.get(input.ID); err != nil {
+			return err
+		}
+
+		s, err := r.SchemaFor(ctx, r.Name(User{}))
 		if err != nil {
 			return err
 		}
 
-		err = r.Render(output.Writer, jsonform.Page{}, jsonform.Form{
+		// Value is loaded from ValueURL with ETag, that is sent back in If-Match header on submit.
+		err = r.RenderContext(ctx, output.Writer, jsonform.Page{}, jsonform.Form{
 			Title:         "Update User",
 			SubmitMethod:  http.MethodPut,
-			SubmitURL:     "/user/" + strconv.Itoa(input.ID) + ".json",
-			Value:         user,
+			SubmitURL:     "/user/{id}.json",
+			ValueURL:      "/user/{id}.json",
+			URLParams:     map[string]string{"id": strconv.Itoa(input.ID)},
+			Schema:        s.WithSubmit("Update"),
 			SuccessStatus: http.StatusNoContent,
 			OnSuccess:     `function(x){console.log(x);alert("response status: " + x.status)}`,
 		})
@@ -120,8 +178,20 @@ func getUser(ur *userRepo) usecase.Interactor {
 		ID int `path:"id"`
 	}
 
-	u := usecase.NewInteractor(func(ctx context.Context, input getUserInput, output *User) (err error) {
-		*output, err = ur.get(input.ID)
+	type getUserOutput struct {
+		ETag string `header:"ETag" json:"-"`
+
+		User
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, input getUserInput, output *getUserOutput) (err error) {
+		output.User, err = ur.get(input.ID)
+		if err != nil {
+			return err
+		}
+
+		// ETag is sent back by form in If-Match header to detect concurrent changes.
+		output.ETag, err = jsonform.ETag(output.User)
 
 		return err
 	})
@@ -133,19 +203,36 @@ func getUser(ur *userRepo) usecase.Interactor {
 
 func updateUser(ur *userRepo) usecase.Interactor {
 	type updateUserInput struct {
-		ID int `path:"id"`
+		ID      int    `path:"id"`
+		IfMatch string `header:"If-Match"`
 
 		User
 	}
 
-	u := usecase.NewInteractor(func(ctx context.Context, input updateUserInput, output *struct{}) error {
+	type updateUserOutput struct {
+		ETag string `header:"ETag" json:"-"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, input updateUserInput, output *updateUserOutput) error {
+		current, err := ur.get(input.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := jsonform.CheckIfMatch(input.IfMatch, current); err != nil {
+			return err
+		}
+
 		ur.update(input.ID, input.User)
 
-		return nil
+		// New ETag allows subsequent updates from the same form.
+		output.ETag, err = jsonform.ETag(input.User)
+
+		return err
 	})
 	// Describe use case interactor.
-	u.SetTitle("Create User")
-	u.SetExpectedErrors(status.InvalidArgument)
+	u.SetTitle("Update User")
+	u.SetExpectedErrors(status.InvalidArgument, status.FailedPrecondition)
 
 	return u
 }
@@ -36,8 +36,7 @@ func (User) Description() string {
 }
 
 type userRepo struct {
-	st         []User
-	schemaName string
+	st []User
 }
 
 func (r *userRepo) create(u User) {
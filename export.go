@@ -0,0 +1,155 @@
+package jsonform
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// StaticPage is a page rendered by ExportStatic.
+type StaticPage struct {
+	// Path is a file name relative to export directory, for example "users/create.html".
+	Path  string
+	Page  Page
+	Forms []Form
+}
+
+// ExportStatic writes static assets, form.html, schemas ({name}-schema.json) and pages to a directory.
+//
+// Exported files only use relative URLs, so directory can be hosted at any base path.
+// Schemas are exported as seen by anonymous context, see Repository.SchemaFor,
+// names denied by Repository.Authorize are skipped.
+// Forms of pages embed their schemas with a submit button (Form.SubmitText), so they do not depend on page location.
+func (r *Repository) ExportStatic(dir string, pages ...StaticPage) error {
+	ctx := context.Background()
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+
+	if err := exportAssets(dir); err != nil {
+		return err
+	}
+
+	for _, name := range r.NamesFor(ctx) {
+		s, err := r.SchemaFor(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		j, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+
+		if err := writeFile(filepath.Join(dir, name+"-schema.json"), j); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range pages {
+		if err := r.exportPage(ctx, dir, p); err != nil {
+			return fmt.Errorf("exporting page %s: %w", p.Path, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Repository) exportPage(ctx context.Context, dir string, p StaticPage) error {
+	fn := path.Clean(p.Path)
+	if fn == "." || path.IsAbs(fn) || strings.HasPrefix(fn, "../") || fn == ".." {
+		return fmt.Errorf("invalid page path: %s", p.Path)
+	}
+
+	// Assets are referenced relative to page location.
+	p.Page.BaseURL = strings.Repeat("../", strings.Count(fn, "/"))
+	if p.Page.BaseURL == "" {
+		p.Page.BaseURL = "./"
+	}
+
+	forms := make([]Form, 0, len(p.Forms))
+
+	for _, f := range p.Forms {
+		if f.Schema == nil && f.Value == nil && f.SchemaName != "" {
+			s, err := r.SchemaFor(ctx, f.SchemaName)
+			if err != nil {
+				return err
+			}
+
+			f.Schema = s.WithSubmit(f.SubmitText)
+		}
+
+		forms = append(forms, f)
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	if err := r.RenderContext(ctx, buf, p.Page, forms...); err != nil {
+		return err
+	}
+
+	fn = filepath.Join(dir, filepath.FromSlash(fn))
+
+	if err := os.MkdirAll(filepath.Dir(fn), 0o750); err != nil {
+		return err
+	}
+
+	return writeFile(fn, buf.Bytes())
+}
+
+// exportAssets writes decompressed static assets without templates.
+func exportAssets(dir string) error {
+	return fs.WalkDir(staticAssets, "static", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasSuffix(p, "_tmpl.html") {
+			return err
+		}
+
+		data, err := staticAssets.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		name := path.Base(p)
+
+		if strings.HasSuffix(name, ".gz") {
+			zr, err := gzip.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return fmt.Errorf("decompressing %s: %w", name, err)
+			}
+
+			if data, err = io.ReadAll(zr); err != nil {
+				return fmt.Errorf("decompressing %s: %w", name, err)
+			}
+
+			name = strings.TrimSuffix(name, ".gz")
+		}
+
+		return writeFile(filepath.Join(dir, name), data)
+	})
+}
+
+func writeFile(fn string, data []byte) (err error) {
+	f, err := os.Create(filepath.Clean(fn))
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if clErr := f.Close(); clErr != nil && err == nil {
+			err = clErr
+		}
+	}()
+
+	_, err = f.Write(data)
+
+	return err
+}
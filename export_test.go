@@ -0,0 +1,72 @@
+package jsonform_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/jsonschema-go"
+)
+
+func TestRepository_ExportStatic(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+	require.NoError(t, repo.AddNamed(User{}, "user"))
+	require.NoError(t, repo.AddSchemaJSON("secret", []byte(`{"type":"object","properties":{"key":{"type":"string"}}}`)))
+
+	repo.Authorize = func(_ context.Context, name string) error {
+		if name == "secret" {
+			return errors.New("denied")
+		}
+
+		return nil
+	}
+
+	dir := t.TempDir()
+
+	require.NoError(t, repo.ExportStatic(dir,
+		jsonform.StaticPage{
+			Path:  "users/create.html",
+			Forms: []jsonform.Form{{Title: "Create user", SchemaName: "user", SubmitURL: "/users"}},
+		},
+		jsonform.StaticPage{
+			Path:  "index.html",
+			Forms: []jsonform.Form{{Title: "Create user", SchemaName: "user", SubmitURL: "/users", SubmitText: "Create"}},
+		},
+	))
+
+	read := func(name string) string {
+		t.Helper()
+
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+
+		return string(b)
+	}
+
+	assert.Contains(t, read("form.html"), `src="form.js"`)
+	assert.Contains(t, read("form.js"), "JSONForm")
+	assert.Contains(t, read("jquery-3.7.1.min.js"), "jQuery")
+	assert.Contains(t, read("user-schema.json"), `"key":"firstName"`)
+
+	for _, name := range []string{"secret-schema.json", "form_tmpl.html", "jquery-3.7.1.min.js.gz"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.True(t, os.IsNotExist(err), name)
+	}
+
+	page := read("users/create.html")
+	assert.Contains(t, page, `src="../form.js"`)
+	assert.Contains(t, page, `"key":"firstName"`)
+	assert.Contains(t, page, `{"type":"submit","title":"Submit"}`)
+	assert.NotContains(t, page, "/json-form/")
+
+	assert.Contains(t, read("index.html"), `src="./form.js"`)
+	assert.Contains(t, read("index.html"), `{"type":"submit","title":"Create"}`)
+
+	assert.EqualError(t, repo.ExportStatic(dir, jsonform.StaticPage{Path: "../index.html"}),
+		"exporting page ../index.html: invalid page path: ../index.html")
+}
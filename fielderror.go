@@ -0,0 +1,30 @@
+package jsonform
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/swaggest/rest"
+	"github.com/swaggest/usecase/status"
+)
+
+var arrayIndexKey = regexp.MustCompile(`\[([0-9]+)]`)
+
+// FieldError returns validation error of a form field in the format of swaggest/rest,
+// form.js shows message next to the field.
+//
+// Key follows FormItem.Key convention with array indexes, for example "neighbors[1].firstName".
+func FieldError(key, message string) error {
+	return status.Wrap(rest.ValidationErrors{"body": []string{keyPointer(key) + ": " + message}}, status.InvalidArgument)
+}
+
+// keyPointer converts form item key to JSON pointer of value, e.g. "#/neighbors/1/firstName".
+func keyPointer(key string) string {
+	parts := strings.Split(arrayIndexKey.ReplaceAllString(key, ".$1"), ".")
+
+	for i, p := range parts {
+		parts[i] = strings.ReplaceAll(strings.ReplaceAll(p, "~", "~0"), "/", "~1")
+	}
+
+	return "#/" + strings.Join(parts, "/")
+}
@@ -0,0 +1,20 @@
+package jsonform_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/rest"
+)
+
+func TestFieldError(t *testing.T) {
+	code, er := rest.Err(jsonform.FieldError("neighbors[1].firstName", "is already taken"))
+
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "invalid argument: validation failed", er.ErrorText)
+	assert.Equal(t, map[string]interface{}{
+		"body": []string{"#/neighbors/1/firstName: is already taken"},
+	}, er.Context)
+}
@@ -0,0 +1,30 @@
+package jsonform_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormJS(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node is not available")
+	}
+
+	// Files are read here to be tracked by test cache.
+	script, err := os.ReadFile("testdata/form_test.js")
+	require.NoError(t, err)
+
+	source, err := os.ReadFile("static/form.js")
+	require.NoError(t, err)
+
+	cmd := exec.Command(node, "-e", string(script))
+	cmd.Stdin = bytes.NewReader(source)
+
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
@@ -0,0 +1,183 @@
+package jsonform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+)
+
+// FuncMap returns functions to render forms in html/template pages.
+//
+//	{{ jsonformAssets "nonce" .Nonce }}
+//	{{ jsonform "user" "value" .User "submitUrl" "/users" "submitMethod" "POST" "successStatus" 201 }}
+//
+// jsonformAssets renders stylesheets and scripts to include once in the <head> of a page, see Repository.Assets.
+//
+// jsonform renders form of a registered schema name, see Repository.RenderFragmentContext.
+// Options are key-value pairs of Form JSON fields (e.g. "title", "valueUrl", "submitUrl", "successRedirect"),
+// "value", "submitText", "ctx" (context.Context of request for authorization and field policies)
+// and page options ("nonce", "csrfToken", "csrfHeader", "csrfCookie", "baseUrl", "strictCSP").
+// Form parameters are embedded as JSON with escaping for the script context.
+//
+// Forms without "ctx" option are rendered with context.Background(), see FuncMapContext.
+func (r *Repository) FuncMap() template.FuncMap {
+	return r.FuncMapContext(context.Background())
+}
+
+// FuncMapContext returns functions of FuncMap that render forms in context by default,
+// for example with functions of a cloned template per request.
+func (r *Repository) FuncMapContext(ctx context.Context) template.FuncMap {
+	return template.FuncMap{
+		"jsonformAssets": func(options ...interface{}) (template.HTML, error) {
+			var p Page
+
+			if err := parseTemplateOptions(options, &p, nil); err != nil {
+				return "", fmt.Errorf("jsonformAssets: %w", err)
+			}
+
+			return r.Assets(p)
+		},
+		"jsonform": func(name string, args ...interface{}) (template.HTML, error) {
+			res, err := r.templateForm(ctx, name, args)
+			if err != nil {
+				return "", fmt.Errorf("jsonform %s: %w", name, err)
+			}
+
+			return res, nil
+		},
+	}
+}
+
+func (r *Repository) templateForm(ctx context.Context, name string, args []interface{}) (template.HTML, error) {
+	var (
+		p Page
+		f Form
+	)
+
+	if len(args)%2 != 0 {
+		return "", fmt.Errorf("options must be key-value pairs, %d arguments received", len(args))
+	}
+
+	options := make([]interface{}, 0, len(args))
+
+	for i := 0; i < len(args); i += 2 {
+		switch args[i] {
+		case "value":
+			f.Value = args[i+1]
+		case "ctx":
+			c, ok := args[i+1].(context.Context)
+			if !ok {
+				return "", fmt.Errorf("option ctx must be a context.Context, %T received", args[i+1])
+			}
+
+			ctx = c
+		default:
+			options = append(options, args[i], args[i+1])
+		}
+	}
+
+	if err := parseTemplateOptions(options, &p, &f); err != nil {
+		return "", err
+	}
+
+	s, err := r.SchemaFor(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	f.Schema = s.WithSubmit(f.SubmitText)
+
+	// Value is filtered by schema name, as its type may not be registered (e.g. a map or json.RawMessage).
+	if f.Value != nil {
+		if f.Value, err = r.ValueFor(ctx, name, f.Value); err != nil {
+			return "", err
+		}
+	}
+
+	if f.OnBeforeSubmit == "" && f.OnRequestFinished == "" {
+		f.OnBeforeSubmit = "startSpinner"
+		f.OnRequestFinished = "stopSpinner"
+	}
+
+	return r.RenderFragmentContext(ctx, p, f)
+}
+
+// parseTemplateOptions applies key-value pairs to page and form, form is nil for page options only.
+func parseTemplateOptions(options []interface{}, p *Page, f *Form) error {
+	if len(options)%2 != 0 {
+		return fmt.Errorf("options must be key-value pairs, %d arguments received", len(options))
+	}
+
+	formOptions := map[string]interface{}{}
+
+	for i := 0; i < len(options); i += 2 {
+		key, ok := options[i].(string)
+		if !ok {
+			return fmt.Errorf("option name must be a string, %T received", options[i])
+		}
+
+		value := options[i+1]
+
+		var err error
+
+		switch key {
+		case "baseUrl":
+			p.BaseURL, err = stringOption(key, value)
+		case "nonce":
+			p.Nonce, err = stringOption(key, value)
+		case "csrfToken":
+			p.CSRFToken, err = stringOption(key, value)
+		case "csrfHeader":
+			p.CSRFHeader, err = stringOption(key, value)
+		case "csrfCookie":
+			p.CSRFCookie, err = stringOption(key, value)
+		case "strictCSP":
+			if p.StrictCSP, ok = value.(bool); !ok {
+				err = fmt.Errorf("option %s must be a bool, %T received", key, value)
+			}
+		default:
+			if f == nil {
+				return fmt.Errorf("unknown option %s", key)
+			}
+
+			if key == "submitText" {
+				f.SubmitText, err = stringOption(key, value)
+			} else {
+				formOptions[key] = value
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(formOptions) == 0 {
+		return nil
+	}
+
+	j, err := json.Marshal(formOptions)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(j))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(f); err != nil {
+		return fmt.Errorf("invalid form options: %w", err)
+	}
+
+	return nil
+}
+
+func stringOption(key string, value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("option %s must be a string, %T received", key, value)
+	}
+
+	return s, nil
+}
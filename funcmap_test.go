@@ -0,0 +1,101 @@
+package jsonform_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"html/template"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/jsonschema-go"
+)
+
+func TestRepository_FuncMap(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+	require.NoError(t, repo.AddNamed(User{}, "user"))
+
+	tmpl, err := template.New("page").Funcs(repo.FuncMap()).Parse(`<html><head>{{ jsonformAssets "nonce" .Nonce }}</head>` +
+		`<body>{{ jsonform "user" "value" .User "name" "edit" "submitUrl" "/users" "successStatus" 201 "submitText" "Save" "nonce" .Nonce }}</body></html>`)
+	require.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, tmpl.Execute(buf, map[string]interface{}{
+		"Nonce": "abc123",
+		"User":  User{FirstName: "</script><script>alert(1)</script>"},
+	}))
+
+	html := buf.String()
+	assert.Contains(t, html, `<script type="text/javascript" src="form.js" nonce="abc123"></script>`)
+	assert.Contains(t, html, `<form id="schema-form-edit" class="pure-form"></form>`)
+	assert.Contains(t, html, `<script type="text/javascript" nonce="abc123">`)
+	assert.Contains(t, html, `"submitUrl":"/users","successStatus":201`)
+	assert.Contains(t, html, `{"type":"submit","title":"Save"}`)
+	assert.Contains(t, html, `"firstName":"\u003c/script\u003e\u003cscript\u003ealert(1)\u003c/script\u003e"`)
+	assert.NotContains(t, html, `<script>alert(1)`)
+
+	tmpl = template.Must(template.New("page").Funcs(repo.FuncMap()).Parse(`{{ jsonform "user" "strictCSP" true }}`))
+	buf.Reset()
+	require.NoError(t, tmpl.Execute(buf, nil))
+	assert.Contains(t, buf.String(), `data-jsonform="{&#34;name&#34;:`)
+	assert.NotContains(t, buf.String(), `<script`)
+
+	for tpl, msg := range map[string]string{
+		`{{ jsonform "unknown" }}`:                  `jsonform unknown: not found: unknown schema unknown`,
+		`{{ jsonform "user" "foo" "bar" }}`:         `jsonform user: invalid form options: json: unknown field "foo"`,
+		`{{ jsonform "user" 1 "bar" }}`:             `jsonform user: option name must be a string, int received`,
+		`{{ jsonform "user" "title" }}`:             `jsonform user: options must be key-value pairs, 1 arguments received`,
+		`{{ jsonform "user" "ctx" "bar" }}`:         `jsonform user: option ctx must be a context.Context, string received`,
+		`{{ jsonform "user" "nonce" 1 }}`:           `jsonform user: option nonce must be a string, int received`,
+		`{{ jsonformAssets "submitUrl" "/users" }}`: `jsonformAssets: unknown option submitUrl`,
+		`{{ jsonformAssets "nonce" }}`:              `jsonformAssets: options must be key-value pairs, 1 arguments received`,
+	} {
+		tmpl := template.Must(template.New("page").Funcs(repo.FuncMap()).Parse(tpl))
+		assert.ErrorContains(t, tmpl.Execute(buf, nil), msg, tpl)
+	}
+}
+
+func TestRepository_FuncMapContext(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+	require.NoError(t, repo.AddNamed(User{}, "user"))
+
+	repo.AddPolicy(func(ctx context.Context, schemaName string, item jsonform.FormItem) jsonform.FieldAccess {
+		if item.Key == "bio" && ctx.Value(roleCtxKey{}) != "admin" {
+			return jsonform.FieldRemoved
+		}
+
+		return jsonform.FieldEditable
+	})
+
+	admin := context.WithValue(context.Background(), roleCtxKey{}, "admin")
+	buf := bytes.NewBuffer(nil)
+
+	tmpl := template.Must(template.New("page").Funcs(repo.FuncMap()).Parse(`{{ jsonform "user" }}`))
+	require.NoError(t, tmpl.Execute(buf, nil))
+	assert.NotContains(t, buf.String(), `"key":"bio"`)
+
+	buf.Reset()
+	tmpl = template.Must(template.New("page").Funcs(repo.FuncMap()).Parse(`{{ jsonform "user" "ctx" .Ctx }}`))
+	require.NoError(t, tmpl.Execute(buf, map[string]interface{}{"Ctx": admin}))
+	assert.Contains(t, buf.String(), `"key":"bio"`)
+
+	buf.Reset()
+	tmpl = template.Must(template.New("page").Funcs(repo.FuncMapContext(admin)).Parse(`{{ jsonform "user" }}`))
+	require.NoError(t, tmpl.Execute(buf, nil))
+	assert.Contains(t, buf.String(), `"key":"bio"`)
+
+	// Removed fields are not embedded with values of types that are not registered.
+	value := map[string]interface{}{"firstName": "John", "bio": "TOPSECRET"}
+	tmpl = template.Must(template.New("page").Funcs(repo.FuncMap()).Parse(`{{ jsonform "user" "value" .V }}`))
+
+	buf.Reset()
+	require.NoError(t, tmpl.Execute(buf, map[string]interface{}{"V": value}))
+	assert.Contains(t, buf.String(), `"firstName":"John"`)
+	assert.NotContains(t, buf.String(), "TOPSECRET")
+
+	buf.Reset()
+	require.NoError(t, tmpl.Execute(buf, map[string]interface{}{"V": json.RawMessage(`{"firstName":"John","bio":"TOPSECRET"}`)}))
+	assert.NotContains(t, buf.String(), "TOPSECRET")
+}
@@ -2,21 +2,62 @@ package jsonform
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 
+	"github.com/swaggest/rest"
 	"github.com/swaggest/rest/web"
 	"github.com/swaggest/usecase"
 	"github.com/swaggest/usecase/status"
 )
 
 // Mount attaches handlers to web service.
+//
+// Schema endpoint is added as a use case to be documented in OpenAPI schema.
 func (r *Repository) Mount(s *web.Service, prefix string) {
-	r.baseURL = prefix
+	h := r.Handler(prefix)
 
 	s.Get(prefix+"{name}-schema.json", r.GetSchema())
-	s.Mount(prefix, http.StripPrefix(prefix, staticServer))
+	s.Mount(prefix, h)
+}
+
+// Handler returns HTTP handler of static assets and schemas ({name}-schema.json) to serve at prefix.
+//
+// It can be used with any router, for example:
+//
+//	mux.Handle("/json-form/", repo.Handler("/json-form/"))
+func (r *Repository) Handler(prefix string) http.Handler {
+	r.baseURL = prefix
+
+	static := http.StripPrefix(prefix, staticServer)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		p := strings.TrimPrefix(req.URL.Path, prefix)
+
+		if name := strings.TrimSuffix(p, "-schema.json"); name != p && !strings.Contains(name, "/") {
+			if req.Method != http.MethodGet && req.Method != http.MethodHead {
+				w.Header().Set("Allow", "GET, HEAD")
+				writeError(w, rest.HTTPCodeAsError(http.StatusMethodNotAllowed))
+
+				return
+			}
+
+			fs, err := r.SchemaFor(req.Context(), name)
+			if err != nil {
+				writeError(w, err)
+
+				return
+			}
+
+			writeJSON(w, http.StatusOK, fs)
+
+			return
+		}
+
+		static.ServeHTTP(w, req)
+	})
 }
 
 type schemaReq struct {
@@ -26,6 +67,10 @@ type schemaReq struct {
 type schemaName string
 
 func (s schemaName) Enum() []interface{} {
+	if s == "" {
+		return nil
+	}
+
 	ss := strings.Split(string(s), ",")
 	enum := make([]interface{}, 0, len(ss))
 
@@ -37,9 +82,14 @@ func (s schemaName) Enum() []interface{} {
 }
 
 // GetSchema returns JSONForm schema.
+//
+// Schema names are documented as enum unless Repository.Authorize is set,
+// static documentation can not depend on the caller.
 func (r *Repository) GetSchema() usecase.Interactor {
-	in := schemaReq{
-		Name: schemaName(strings.Join(r.Names(), ",")),
+	in := schemaReq{}
+
+	if r.Authorize == nil {
+		in.Name = schemaName(strings.Join(r.Names(), ","))
 	}
 
 	u := usecase.NewIOI(in, new(FormSchema), func(ctx context.Context, in, out interface{}) error {
@@ -53,17 +103,40 @@ func (r *Repository) GetSchema() usecase.Interactor {
 			return fmt.Errorf("unexpected output: %T", out)
 		}
 
-		if fs, found := r.schemasByName[string(input.Name)]; found {
-			*output = fs
-
-			return nil
+		fs, err := r.SchemaFor(ctx, string(input.Name))
+		if err != nil {
+			return err
 		}
 
-		return status.NotFound
+		*output = *fs
+
+		return nil
 	})
 
 	u.SetTitle("Get JSONForm Schema")
-	u.SetExpectedErrors(status.NotFound)
+	u.SetExpectedErrors(status.NotFound, status.PermissionDenied)
 
 	return u
 }
+
+// writeError writes error response in the format of swaggest/rest.
+func writeError(w http.ResponseWriter, err error) {
+	code, er := rest.Err(err)
+
+	writeJSON(w, code, er)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		code = http.StatusInternalServerError
+		b = []byte(`{"error":"failed to encode response"}`)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+
+	if _, err := w.Write(b); err != nil {
+		return
+	}
+}
@@ -0,0 +1,112 @@
+package jsonform_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/assertjson"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/jsonschema-go"
+	"github.com/swaggest/openapi-go/openapi31"
+	"github.com/swaggest/rest/web"
+	"github.com/swaggest/usecase/status"
+)
+
+type roleCtxKey struct{}
+
+func TestRepository_Authorize(t *testing.T) {
+	s := web.NewService(openapi31.NewReflector())
+	s.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), roleCtxKey{}, r.Header.Get("X-Role"))))
+		})
+	})
+
+	repo := jsonform.NewRepository(s.OpenAPIReflector().JSONSchemaReflector())
+	repo.Authorize = func(ctx context.Context, name string) error {
+		if name != "user" && ctx.Value(roleCtxKey{}) != "admin" {
+			return status.PermissionDenied
+		}
+
+		return nil
+	}
+
+	require.NoError(t, repo.AddNamed(User{}, "user"))
+	require.NoError(t, repo.AddNamed(UserWithNeighbors{}, "userwithneighbors"))
+	repo.Mount(s, "/json-form/")
+
+	assert.Equal(t, []string{"user", "userwithneighbors"}, repo.Names())
+	assert.Equal(t, []string{"user"}, repo.NamesFor(context.Background()))
+	assert.Equal(t, []string{"user", "userwithneighbors"},
+		repo.NamesFor(context.WithValue(context.Background(), roleCtxKey{}, "admin")))
+
+	req := httptest.NewRequest(http.MethodGet, "/json-form/userwithneighbors-schema.json", nil)
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+
+	req.Header.Set("X-Role", "admin")
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/json-form/user-schema.json", nil)
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	// Existence of denied names is not revealed.
+	req = httptest.NewRequest(http.MethodGet, "/json-form/unknown-schema.json", nil)
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+
+	req.Header.Set("X-Role", "admin")
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+
+	spec, err := json.Marshal(s.OpenAPISchema())
+	require.NoError(t, err)
+	assert.NotContains(t, string(spec), "userwithneighbors")
+}
+
+func TestRepository_Handler(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+	require.NoError(t, repo.AddNamed(User{}, "user"))
+
+	mux := http.NewServeMux()
+	mux.Handle("/json-form/", repo.Handler("/json-form/"))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/json-form/user-schema.json", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rw.Header().Get("Content-Type"))
+	assertjson.EqMarshal(t, rw.Body.String(), repo.SchemaByName("user"))
+
+	rw = httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/json-form/unknown-schema.json", nil))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+	assert.Equal(t, `{"status":"NOT_FOUND","error":"not found: unknown schema unknown"}`, rw.Body.String())
+
+	rw = httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/json-form/user-schema.json", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+
+	rw = httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/json-form/form.js", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Contains(t, rw.Body.String(), "function JSONForm()")
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.Render(buf, jsonform.Page{}, jsonform.Form{SchemaName: "user"}))
+	assert.Contains(t, buf.String(), `src="/json-form/form.js"`)
+}
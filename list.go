@@ -0,0 +1,346 @@
+package jsonform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+)
+
+// List describes a table of values of a registered schema.
+type List struct {
+	Title string
+
+	// SchemaName is a name of schema of list items, schema of Items element is used if empty.
+	SchemaName string
+
+	// Items is a slice of values to show, alternatively ListURL can be used.
+	//
+	// Items are embedded in the page as JSON with only fields of columns and of EditURL and DeleteURL placeholders.
+	Items interface{}
+
+	// ListURL is a URL of JSON array of values to load in browser.
+	ListURL string
+
+	// ValueKey is a name of item field that contains value if items are envelopes,
+	// for example "value" for items like {"id":1,"value":{...}}.
+	ValueKey string
+
+	// Columns are keys of form items to show, default is all keys that are not arrays or hidden.
+	Columns []string
+
+	// Sortable are keys of columns that can be sorted.
+	Sortable []string
+
+	// CreateURL is a URL of create form, link is shown above the table.
+	CreateURL string
+
+	// EditURL is a URL template of edit form, {name} placeholders are filled with item fields, e.g. "/edit-user/{id}".
+	EditURL string
+
+	// DeleteURL is a URL template to send DELETE request after confirmation, placeholders are filled as for EditURL.
+	DeleteURL string
+
+	// Headers, OnHeaders and Credentials are applied to list and delete requests as for Form,
+	// OnHeaders must be a name of function registered with JSONForm.registerCallback or a global function.
+	Headers     map[string]string
+	OnHeaders   string
+	Credentials string
+}
+
+// listColumn is a column of list table.
+type listColumn struct {
+	Key      string            `json:"key"`
+	Title    string            `json:"title"`
+	Sortable bool              `json:"sortable,omitempty"`
+	TitleMap map[string]string `json:"titleMap,omitempty"`
+}
+
+// listParams are parameters of list table for form.js.
+type listParams struct {
+	Columns   []listColumn `json:"columns"`
+	Items     interface{}  `json:"items,omitempty"`
+	ListURL   string       `json:"listUrl,omitempty"`
+	ValueKey  string       `json:"valueKey,omitempty"`
+	EditURL   string       `json:"editUrl,omitempty"`
+	DeleteURL string       `json:"deleteUrl,omitempty"`
+
+	Headers     map[string]string `json:"headers,omitempty"`
+	OnHeaders   string            `json:"onHeaders,omitempty"`
+	Credentials string            `json:"credentials,omitempty"`
+}
+
+var listTemplate = loadTemplate("list_tmpl.html")
+
+// RenderList renders table of values as web page.
+//
+// Repository.Authorize is not checked, field policies are applied as for an anonymous caller.
+func (r *Repository) RenderList(w io.Writer, p Page, l List) error {
+	return r.RenderListContext(serverSide(), w, p, l)
+}
+
+// RenderListContext renders table of values as web page with field policies applied in context,
+// columns of hidden and removed fields are not shown, see Repository.SchemaFor.
+func (r *Repository) RenderListContext(ctx context.Context, w io.Writer, p Page, l List) error {
+	fs, err := r.listSchema(ctx, l)
+	if err != nil {
+		return err
+	}
+
+	if err := checkCredentials(l.Credentials); err != nil {
+		return err
+	}
+
+	if l.OnHeaders != "" && !callbackName.MatchString(l.OnHeaders) {
+		return fmt.Errorf("list OnHeaders must be a function name: %q", l.OnHeaders)
+	}
+
+	params := listParams{
+		Items:       l.Items,
+		ListURL:     l.ListURL,
+		ValueKey:    l.ValueKey,
+		EditURL:     l.EditURL,
+		DeleteURL:   l.DeleteURL,
+		Headers:     l.Headers,
+		OnHeaders:   l.OnHeaders,
+		Credentials: l.Credentials,
+	}
+
+	if params.Columns, err = listColumns(fs, l); err != nil {
+		return err
+	}
+
+	if params.Items, err = projectItems(l, params.Columns); err != nil {
+		return err
+	}
+
+	j, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	d := struct {
+		Page
+		List    List
+		Columns []listColumn
+		Params  string
+		BaseURL string
+	}{
+		Page:    p,
+		List:    l,
+		Columns: params.Columns,
+		Params:  string(j),
+		BaseURL: r.baseURL,
+	}
+
+	if p.BaseURL != "" {
+		d.BaseURL = p.BaseURL
+	}
+
+	if d.Title == "" {
+		d.Title = l.Title
+	}
+
+	if (d.CSRFToken != "" || d.CSRFCookie != "") && d.CSRFHeader == "" {
+		d.CSRFHeader = CSRFHeader
+	}
+
+	return listTemplate.Execute(w, d)
+}
+
+func (r *Repository) listSchema(ctx context.Context, l List) (*FormSchema, error) {
+	if l.SchemaName != "" {
+		return r.SchemaFor(ctx, l.SchemaName)
+	}
+
+	items := reflect.ValueOf(l.Items)
+	if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+		return nil, errors.New("list schema is not defined, SchemaName or Items slice is required")
+	}
+
+	return r.formSchema(ctx, reflect.New(items.Type().Elem()).Elem().Interface())
+}
+
+func listColumns(fs *FormSchema, l List) ([]listColumn, error) {
+	items := map[string]FormItem{}
+
+	var keys []string
+
+	walkFormItems(fs.Form, func(fi FormItem) {
+		if fi.Key == "" {
+			return
+		}
+
+		items[fi.Key] = fi
+
+		if fi.FormType != "array" && fi.FormType != "hidden" && !strings.Contains(fi.Key, "[]") {
+			keys = append(keys, fi.Key)
+		}
+	})
+
+	if len(l.Columns) > 0 {
+		keys = l.Columns
+	}
+
+	sortable := map[string]bool{}
+	for _, k := range l.Sortable {
+		sortable[k] = true
+	}
+
+	columns := make([]listColumn, 0, len(keys))
+
+	for _, k := range keys {
+		fi, ok := items[k]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %s", k)
+		}
+
+		if fi.FormType == "hidden" {
+			continue
+		}
+
+		columns = append(columns, listColumn{
+			Key:      k,
+			Title:    itemTitle(&fs.Schema, fi),
+			Sortable: sortable[k],
+			TitleMap: fi.TitleMap,
+		})
+	}
+
+	return columns, nil
+}
+
+// projectItems returns items with only fields that are used by columns and URL placeholders,
+// so that hidden and removed fields are not embedded in the page.
+func projectItems(l List, columns []listColumn) (interface{}, error) {
+	if l.Items == nil {
+		return nil, nil
+	}
+
+	v, err := toJSONValue(l.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, errors.New("list items must be a slice")
+	}
+
+	var keys []string
+
+	for _, u := range []string{l.EditURL, l.DeleteURL} {
+		for _, m := range pathParam.FindAllStringSubmatch(u, -1) {
+			keys = append(keys, m[1])
+		}
+	}
+
+	for _, c := range columns {
+		if l.ValueKey != "" {
+			keys = append(keys, l.ValueKey+"."+c.Key)
+		} else {
+			keys = append(keys, c.Key)
+		}
+	}
+
+	res := make([]interface{}, 0, len(items))
+
+	for _, item := range items {
+		p := map[string]interface{}{}
+
+		for _, k := range keys {
+			copyValue(p, item, strings.Split(k, "."))
+		}
+
+		res = append(res, p)
+	}
+
+	return res, nil
+}
+
+// copyValue copies value by path of property names from src to dst object.
+func copyValue(dst map[string]interface{}, src interface{}, parts []string) {
+	m, ok := src.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	v, ok := m[parts[0]]
+	if !ok {
+		return
+	}
+
+	if len(parts) == 1 {
+		dst[parts[0]] = v
+
+		return
+	}
+
+	if _, ok := v.(map[string]interface{}); !ok {
+		return
+	}
+
+	d, ok := dst[parts[0]].(map[string]interface{})
+	if !ok {
+		if _, found := dst[parts[0]]; found {
+			return
+		}
+
+		d = map[string]interface{}{}
+		dst[parts[0]] = d
+	}
+
+	copyValue(d, v, parts[1:])
+}
+
+// walkFormItems calls f for every form item including nested ones.
+func walkFormItems(items []FormItem, f func(fi FormItem)) {
+	for _, fi := range items {
+		f(fi)
+		walkFormItems(fi.Items, f)
+	}
+}
+
+// itemTitle returns title of form item, or title of its property schema, or key.
+func itemTitle(schema *jsonschema.Schema, fi FormItem) string {
+	if fi.FormTitle != "" {
+		return fi.FormTitle
+	}
+
+	if s := propertySchema(schema, fi.Key); s != nil && s.Title != nil {
+		return *s.Title
+	}
+
+	return fi.Key
+}
+
+// propertySchema finds schema of a value by form item key, e.g. "user.firstName" or "neighbors[].age".
+func propertySchema(schema *jsonschema.Schema, key string) *jsonschema.Schema {
+	s := schema
+
+	for _, part := range strings.Split(key, ".") {
+		name, arrays := keyPart(part)
+
+		prop, ok := s.Properties[name]
+		if !ok || prop.TypeObject == nil {
+			return nil
+		}
+
+		s = prop.TypeObject
+
+		for i := 0; i < arrays; i++ {
+			if s.Items == nil || s.Items.SchemaOrBool == nil || s.Items.SchemaOrBool.TypeObject == nil {
+				return nil
+			}
+
+			s = s.Items.SchemaOrBool.TypeObject
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,115 @@
+package jsonform_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/jsonschema-go"
+)
+
+func TestRepository_RenderList(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.RenderList(buf, jsonform.Page{}, jsonform.List{
+		Title:     "Users",
+		Items:     []User{{FirstName: "John", LastName: "Doe", Age: 30}},
+		Sortable:  []string{"age"},
+		CreateURL: "/create-user",
+		EditURL:   "/edit-user/{firstName}",
+	}))
+
+	html := buf.String()
+	assert.Contains(t, html, `<title>Users</title>`)
+	assert.Contains(t, html, `<a href="/create-user" class="pure-button pure-button-primary">Create</a>`)
+	assert.Contains(t, html, `<th data-key="firstName">First name</th><th data-key="lastName">Last name</th>`+
+		`<th data-key="locale">User locale</th><th data-key="age">Age</th><th data-key="status">Status</th>`+
+		`<th data-key="bio">Bio</th>`)
+	assert.Contains(t, html, `{&#34;key&#34;:&#34;age&#34;,&#34;title&#34;:&#34;Age&#34;,&#34;sortable&#34;:true}`)
+	assert.Contains(t, html, `&#34;items&#34;:[{&#34;age&#34;:30,&#34;bio&#34;:&#34;&#34;,&#34;firstName&#34;:&#34;John&#34;`)
+	assert.Contains(t, html, `&#34;editUrl&#34;:&#34;/edit-user/{firstName}&#34;`)
+}
+
+func TestRepository_RenderListContext(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+	require.NoError(t, repo.AddNamed(UserWithNeighbors{}, "user"))
+
+	repo.AddPolicy(func(ctx context.Context, schemaName string, item jsonform.FormItem) jsonform.FieldAccess {
+		switch item.Key {
+		case "user.status":
+			return jsonform.FieldHidden
+		case "user.bio":
+			return jsonform.FieldRemoved
+		}
+
+		return jsonform.FieldEditable
+	})
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.RenderListContext(context.Background(), buf, jsonform.Page{BaseURL: "/assets/"}, jsonform.List{
+		SchemaName: "user",
+		ListURL:    "/users.json",
+		DeleteURL:  "/user/{id}",
+	}))
+
+	html := buf.String()
+	assert.Contains(t, html, `src="/assets/form.js"`)
+	assert.Contains(t, html, `<th data-key="user.firstName">First name</th><th data-key="user.lastName">Last name</th>`+
+		`<th data-key="user.locale">User locale</th><th data-key="user.age">Age</th>`)
+	assert.NotContains(t, html, `user.status`)
+	assert.NotContains(t, html, `user.bio`)
+	assert.NotContains(t, html, `neighbors`)
+	assert.Contains(t, html, `&#34;listUrl&#34;:&#34;/users.json&#34;`)
+
+	buf.Reset()
+	require.NoError(t, repo.RenderList(buf, jsonform.Page{}, jsonform.List{
+		SchemaName: "user",
+		Items: []map[string]interface{}{{
+			"id":    7,
+			"token": "secret",
+			"user":  map[string]interface{}{"firstName": "John", "status": "active", "bio": "confidential"},
+		}},
+		EditURL: "/user/{id}",
+	}))
+
+	html = buf.String()
+	assert.Contains(t, html, `&#34;items&#34;:[{&#34;id&#34;:7,&#34;user&#34;:{&#34;firstName&#34;:&#34;John&#34;}}]`)
+	assert.NotContains(t, html, `secret`)
+	assert.NotContains(t, html, `active`)
+	assert.NotContains(t, html, `confidential`)
+
+	err := repo.RenderList(buf, jsonform.Page{}, jsonform.List{SchemaName: "user", Columns: []string{"user.bio"}})
+	assert.EqualError(t, err, "unknown column user.bio")
+
+	err = repo.RenderList(buf, jsonform.Page{}, jsonform.List{SchemaName: "unknown"})
+	assert.Error(t, err)
+
+	err = repo.RenderList(buf, jsonform.Page{}, jsonform.List{})
+	assert.EqualError(t, err, "list schema is not defined, SchemaName or Items slice is required")
+}
+
+func TestRepository_RenderList_requestOptions(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.RenderList(buf, jsonform.Page{}, jsonform.List{
+		Items:       []User{},
+		ListURL:     "/users.json",
+		Headers:     map[string]string{"X-Tenant-ID": "t1"},
+		OnHeaders:   "apiHeaders",
+		Credentials: "omit",
+	}))
+
+	html := buf.String()
+	assert.Contains(t, html, `&#34;headers&#34;:{&#34;X-Tenant-ID&#34;:&#34;t1&#34;}`)
+	assert.Contains(t, html, `&#34;onHeaders&#34;:&#34;apiHeaders&#34;`)
+	assert.Contains(t, html, `&#34;credentials&#34;:&#34;omit&#34;`)
+
+	assert.Error(t, repo.RenderList(buf, jsonform.Page{}, jsonform.List{Items: []User{}, Credentials: "always"}))
+	assert.Error(t, repo.RenderList(buf, jsonform.Page{}, jsonform.List{Items: []User{}, OnHeaders: "alert(1)"}))
+	assert.Error(t, repo.Render(buf, jsonform.Page{}, jsonform.Form{Value: User{}, Credentials: "always"}))
+}
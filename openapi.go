@@ -0,0 +1,429 @@
+package jsonform
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/swaggest/openapi-go/openapi3"
+	"github.com/swaggest/openapi-go/openapi31"
+	"gopkg.in/yaml.v2"
+)
+
+// AddFromOpenAPI registers form schema of an operation from OpenAPI 3.0 or 3.1 document in JSON or YAML format.
+//
+// Schema is named by operation ID. Form is created from JSON (or form data) request body schema,
+// or from query and path parameters if operation has no request body.
+func (r *Repository) AddFromOpenAPI(spec []byte, operationID string) error {
+	doc, err := parseOpenAPI(spec)
+	if err != nil {
+		return err
+	}
+
+	found := false
+
+	err = doc.walkOperations(func(id string, op, pathItem *orderedMap) error {
+		if id != operationID {
+			return nil
+		}
+
+		found = true
+
+		schema, ok, err := doc.operationSchema(op, pathItem)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return fmt.Errorf("operation %s has no request body or parameters", id)
+		}
+
+		return r.addRawSchema(id, schema)
+	})
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("operation %s not found", operationID)
+	}
+
+	return nil
+}
+
+// AddAllFromOpenAPI registers form schemas of all operations with ID from OpenAPI 3.0 or 3.1 document.
+//
+// Operations without request body and parameters are skipped, see AddFromOpenAPI.
+func (r *Repository) AddAllFromOpenAPI(spec []byte) error {
+	doc, err := parseOpenAPI(spec)
+	if err != nil {
+		return err
+	}
+
+	return doc.walkOperations(func(id string, op, pathItem *orderedMap) error {
+		if id == "" {
+			return nil
+		}
+
+		schema, found, err := doc.operationSchema(op, pathItem)
+		if err != nil || !found {
+			return err
+		}
+
+		return r.addRawSchema(id, schema)
+	})
+}
+
+type openAPIDoc struct {
+	root *orderedMap
+	v30  bool
+}
+
+func parseOpenAPI(spec []byte) (*openAPIDoc, error) {
+	var ms yaml.MapSlice
+
+	if err := yaml.Unmarshal(spec, &ms); err != nil {
+		return nil, fmt.Errorf("parsing OpenAPI document: %w", err)
+	}
+
+	doc := &openAPIDoc{}
+	doc.root, _ = fromYAML(ms).(*orderedMap)
+
+	v, _ := doc.root.get("openapi")
+	version := fmt.Sprint(v)
+
+	switch {
+	case strings.HasPrefix(version, "3.0"):
+		doc.v30 = true
+
+		var s openapi3.Spec
+
+		if err := s.UnmarshalYAML(spec); err != nil {
+			return nil, fmt.Errorf("invalid OpenAPI 3.0 document: %w", err)
+		}
+	case strings.HasPrefix(version, "3.1"):
+		var s openapi31.Spec
+
+		if err := s.UnmarshalYAML(spec); err != nil {
+			return nil, fmt.Errorf("invalid OpenAPI 3.1 document: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported OpenAPI version: %s", version)
+	}
+
+	return doc, nil
+}
+
+// fromYAML converts decoded YAML value to a JSON value with ordered objects.
+func fromYAML(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case yaml.MapSlice:
+		m := newOrderedMap()
+
+		for _, item := range vv {
+			m.set(fmt.Sprint(item.Key), fromYAML(item.Value))
+		}
+
+		return m
+	case []interface{}:
+		res := make([]interface{}, 0, len(vv))
+
+		for _, item := range vv {
+			res = append(res, fromYAML(item))
+		}
+
+		return res
+	default:
+		return v
+	}
+}
+
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+func (d *openAPIDoc) walkOperations(f func(id string, op, pathItem *orderedMap) error) error {
+	paths := d.root.object("paths")
+	if paths == nil {
+		return nil
+	}
+
+	for _, p := range paths.keys {
+		pathItem := paths.object(p)
+		if pathItem == nil {
+			continue
+		}
+
+		for _, method := range httpMethods {
+			op := pathItem.object(method)
+			if op == nil {
+				continue
+			}
+
+			if err := f(op.str("operationId"), op, pathItem); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// operationSchema returns JSON Schema of operation request, it is not found if operation has no request body and parameters.
+func (d *openAPIDoc) operationSchema(op, pathItem *orderedMap) (schema *orderedMap, found bool, err error) {
+	if rb := op.object("requestBody"); rb != nil {
+		schema, err = d.requestBodySchema(rb)
+	} else {
+		schema, err = d.parametersSchema(op, pathItem)
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	if props := schema.object("properties"); props == nil || len(props.keys) == 0 {
+		return nil, false, nil
+	}
+
+	if schema.str("title") == "" && op.str("summary") != "" {
+		schema.set("title", op.str("summary"))
+	}
+
+	return schema, true, nil
+}
+
+func (d *openAPIDoc) requestBodySchema(rb *orderedMap) (*orderedMap, error) {
+	rb, err := d.deref(rb)
+	if err != nil {
+		return nil, err
+	}
+
+	content := rb.object("content")
+	if content == nil {
+		return nil, errors.New("request body has no content")
+	}
+
+	var mediaType *orderedMap
+
+	for _, preferred := range []func(ct string) bool{
+		func(ct string) bool { return ct == "application/json" },
+		func(ct string) bool { return strings.HasSuffix(ct, "json") },
+		func(ct string) bool { return ct == "application/x-www-form-urlencoded" || ct == "multipart/form-data" },
+	} {
+		for _, ct := range content.keys {
+			if preferred(ct) && mediaType == nil {
+				mediaType = content.object(ct)
+			}
+		}
+	}
+
+	if mediaType == nil || mediaType.object("schema") == nil {
+		return nil, errors.New("request body has no JSON or form data schema")
+	}
+
+	s, err := d.inline(mediaType.object("schema"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, ok := s.(*orderedMap)
+	if !ok {
+		return nil, errors.New("request body schema is not an object")
+	}
+
+	return schema, nil
+}
+
+func (d *openAPIDoc) parametersSchema(op, pathItem *orderedMap) (*orderedMap, error) {
+	props := newOrderedMap()
+
+	var required []interface{}
+
+	for _, item := range []*orderedMap{pathItem, op} {
+		params, _ := item.values["parameters"].([]interface{})
+
+		for _, p := range params {
+			param, ok := p.(*orderedMap)
+			if !ok {
+				continue
+			}
+
+			param, err := d.deref(param)
+			if err != nil {
+				return nil, err
+			}
+
+			name := param.str("name")
+			if in := param.str("in"); (in != "query" && in != "path") || param.object("schema") == nil {
+				continue
+			}
+
+			s, err := d.inline(param.object("schema"), nil)
+			if err != nil {
+				return nil, err
+			}
+
+			schema, _ := s.(*orderedMap)
+			if desc := param.str("description"); desc != "" && schema.str("description") == "" {
+				schema.set("description", desc)
+			}
+
+			props.set(name, schema)
+
+			if r, _ := param.get("required"); r == true {
+				required = append(required, name)
+			}
+		}
+	}
+
+	schema := newOrderedMap()
+	schema.set("type", "object")
+	schema.set("properties", props)
+
+	if len(required) > 0 {
+		schema.set("required", required)
+	}
+
+	return schema, nil
+}
+
+// deref resolves $ref of an object.
+func (d *openAPIDoc) deref(m *orderedMap) (*orderedMap, error) {
+	for i := 0; m.str("$ref") != ""; i++ {
+		if i > 10 {
+			return nil, fmt.Errorf("too many references: %s", m.str("$ref"))
+		}
+
+		target, err := d.lookup(m.str("$ref"))
+		if err != nil {
+			return nil, err
+		}
+
+		m = target
+	}
+
+	return m, nil
+}
+
+// lookup finds object in document by local reference, e.g. "#/components/schemas/User".
+func (d *openAPIDoc) lookup(ref string) (*orderedMap, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported reference: %s", ref)
+	}
+
+	m := d.root
+
+	for _, part := range strings.Split(ref[2:], "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+
+		if p, err := url.PathUnescape(part); err == nil {
+			part = p
+		}
+
+		if m = m.object(part); m == nil {
+			return nil, fmt.Errorf("reference not found: %s", ref)
+		}
+	}
+
+	return m, nil
+}
+
+// inline returns a copy of schema with local references replaced by referenced schemas.
+func (d *openAPIDoc) inline(v interface{}, refs []string) (interface{}, error) {
+	switch vv := v.(type) {
+	case *orderedMap:
+		if ref := vv.str("$ref"); ref != "" {
+			for _, r := range refs {
+				if r == ref {
+					return nil, fmt.Errorf("recursive schema is not supported: %s", ref)
+				}
+			}
+
+			target, err := d.lookup(ref)
+			if err != nil {
+				return nil, err
+			}
+
+			res, err := d.inline(target, append(refs, ref))
+			if err != nil {
+				return nil, err
+			}
+
+			m, _ := res.(*orderedMap)
+
+			// Sibling keywords of reference override referenced schema.
+			for _, k := range vv.keys {
+				if k != "$ref" {
+					sibling, err := d.inline(vv.values[k], refs)
+					if err != nil {
+						return nil, err
+					}
+
+					m.set(k, sibling)
+				}
+			}
+
+			return m, nil
+		}
+
+		m := newOrderedMap()
+
+		for _, k := range vv.keys {
+			val, err := d.inline(vv.values[k], refs)
+			if err != nil {
+				return nil, err
+			}
+
+			m.set(k, val)
+		}
+
+		if d.v30 {
+			convertOpenAPI30(m)
+		}
+
+		return m, nil
+	case []interface{}:
+		res := make([]interface{}, 0, len(vv))
+
+		for _, item := range vv {
+			val, err := d.inline(item, refs)
+			if err != nil {
+				return nil, err
+			}
+
+			res = append(res, val)
+		}
+
+		return res, nil
+	default:
+		return v, nil
+	}
+}
+
+// convertOpenAPI30 converts OpenAPI 3.0 schema keywords to JSON Schema.
+func convertOpenAPI30(m *orderedMap) {
+	if nullable, _ := m.get("nullable"); nullable == true {
+		if t := m.str("type"); t != "" {
+			m.set("type", []interface{}{t, "null"})
+		}
+
+		m.delete("nullable")
+	}
+
+	for _, k := range []string{"Minimum", "Maximum"} {
+		exclusive, ok := m.get("exclusive" + k)
+		if !ok {
+			continue
+		}
+
+		if exclusive, isBool := exclusive.(bool); isBool {
+			limit, hasLimit := m.get(strings.ToLower(k))
+
+			if exclusive && hasLimit {
+				m.set("exclusive"+k, limit)
+				m.delete(strings.ToLower(k))
+			} else {
+				m.delete("exclusive" + k)
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package jsonform_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/assertjson"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/jsonschema-go"
+)
+
+const petstore30 = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      operationId: findPets
+      parameters:
+        - name: tag
+          in: query
+          description: Tag to filter by.
+          required: true
+          schema:
+            type: string
+        - $ref: '#/components/parameters/limit'
+        - name: X-Trace
+          in: header
+          schema:
+            type: string
+      responses:
+        '200':
+          description: OK
+    post:
+      operationId: addPet
+      summary: Add a pet
+      requestBody:
+        $ref: '#/components/requestBodies/Pet'
+      responses:
+        '201':
+          description: Created
+  /pets/{id}:
+    delete:
+      operationId: deletePet
+      responses:
+        '204':
+          description: Deleted
+components:
+  parameters:
+    limit:
+      name: limit
+      in: query
+      schema:
+        type: integer
+        minimum: 1
+  requestBodies:
+    Pet:
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Pet'
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          title: Name
+        tag:
+          type: string
+          nullable: true
+        age:
+          type: integer
+          minimum: 0
+          exclusiveMinimum: true
+        owner:
+          $ref: '#/components/schemas/Owner'
+        toys:
+          type: array
+          items:
+            type: object
+            properties:
+              title:
+                type: string
+    Owner:
+      type: object
+      properties:
+        email:
+          type: string
+          format: email
+`
+
+func TestRepository_AddFromOpenAPI(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	require.NoError(t, repo.AddFromOpenAPI([]byte(petstore30), "addPet"))
+	assertjson.EqMarshal(t, `{
+	  "form":[
+		{"key":"name"},{"key":"tag"},{"key":"age"},{"key":"owner.email"},
+		{"key":"toys","type":"array","items":[{"type":"section","items":[{"key":"toys[].title"}]}]}
+	  ],
+	  "schema":{
+		"title":"Add a pet",
+		"properties":{
+		  "age":{"exclusiveMinimum":0,"type":"integer"},
+		  "name":{"title":"Name","type":"string","required":true},
+		  "owner":{"properties":{"email":{"type":"string","format":"email"}},"type":"object"},
+		  "tag":{"type":["string","null"]},
+		  "toys":{
+			"items":{"properties":{"title":{"type":"string"}},"type":"object"},
+			"type":"array"
+		  }
+		},
+		"type":"object"
+	  }
+	}`, repo.SchemaByName("addPet"))
+
+	require.NoError(t, repo.AddFromOpenAPI([]byte(petstore30), "findPets"))
+	assertjson.EqMarshal(t, `{
+	  "form":[{"key":"tag"},{"key":"limit"}],
+	  "schema":{
+		"properties":{
+		  "limit":{"minimum":1,"type":"integer"},
+		  "tag":{"description":"Tag to filter by.","type":"string","required":true}
+		},
+		"type":"object"
+	  }
+	}`, repo.SchemaByName("findPets"))
+
+	assert.EqualError(t, repo.AddFromOpenAPI([]byte(petstore30), "deletePet"),
+		"operation deletePet has no request body or parameters")
+	assert.EqualError(t, repo.AddFromOpenAPI([]byte(petstore30), "unknown"), "operation unknown not found")
+	assert.EqualError(t, repo.AddFromOpenAPI([]byte(petstore30), "addPet"), "schema addPet is already added")
+	assert.EqualError(t, repo.AddFromOpenAPI([]byte(`{"openapi":"2.0"}`), "addPet"), "unsupported OpenAPI version: 2.0")
+}
+
+func TestRepository_AddAllFromOpenAPI(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	require.NoError(t, repo.AddAllFromOpenAPI([]byte(petstore30)))
+	assert.Equal(t, []string{"addPet", "findPets"}, repo.Names())
+
+	require.NoError(t, repo.AddAllFromOpenAPI([]byte(`{
+	  "openapi":"3.1.0","info":{"title":"Users","version":"v1"},
+	  "paths":{
+		"/users":{
+		  "put":{
+			"operationId":"updateUser",
+			"requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/User"}}}}
+		  }
+		}
+	  },
+	  "components":{
+		"schemas":{
+		  "User":{
+			"type":"object","required":["firstName"],
+			"properties":{"lastName":{"type":["string","null"]},"firstName":{"type":"string"}}
+		  }
+		}
+	  }
+	}`)))
+	assertjson.EqMarshal(t, `{
+	  "form":[{"key":"lastName"},{"key":"firstName"}],
+	  "schema":{
+		"properties":{
+		  "firstName":{"type":"string","required":true},"lastName":{"type":["string","null"]}
+		},
+		"type":"object"
+	  }
+	}`, repo.SchemaByName("updateUser"))
+
+	assert.EqualError(t, repo.AddAllFromOpenAPI([]byte(`{
+	  "openapi":"3.1.0","info":{"title":"Users","version":"v1"},
+	  "paths":{
+		"/users":{
+		  "post":{
+			"operationId":"createUser",
+			"requestBody":{
+			  "content":{
+				"application/json":{
+				  "schema":{
+					"type":"object","required":["firstName"],
+					"properties":{
+					  "firstName":{"type":"string","title":"First name"},
+					  "lastName":{"type":["string","null"]},
+					  "self":{"$ref":"#/components/schemas/User"}
+					}
+				  }
+				}
+			  }
+			}
+		  }
+		}
+	  },
+	  "components":{"schemas":{"User":{"$ref":"#/components/schemas/User"}}}
+	}`)), "recursive schema is not supported: #/components/schemas/User")
+}
@@ -0,0 +1,205 @@
+package jsonform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/swaggest/rest"
+	"github.com/swaggest/rest/nethttp"
+	"github.com/swaggest/rest/web"
+	"github.com/swaggest/usecase"
+)
+
+// Operation describes a form of web service operation.
+type Operation struct {
+	Method        string
+	Pattern       string
+	Title         string
+	SchemaName    string
+	SuccessStatus int
+
+	// PathParams are names of path parameters in Pattern.
+	PathParams []string
+}
+
+var (
+	pathParam     = regexp.MustCompile(`{([^}:]+)(:[^}]*)?}`)
+	nonAlphaDigit = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// MountOperations adds forms for operations of web service that accept JSON request body
+// and serves index of operation forms at "operations.html" of repository prefix.
+//
+// Schemas of operations are named by method and route pattern, for example "put-user-id-json".
+// It can be called again to add operations of new routes, operations that are already added are skipped.
+// Repository must be mounted before.
+func (r *Repository) MountOperations(s *web.Service) error {
+	if r.baseURL == "" {
+		return errors.New("repository is not mounted")
+	}
+
+	var ops []Operation
+
+	err := chi.Walk(s, func(method, route string, handler http.Handler, _ ...func(http.Handler) http.Handler) error {
+		var h *nethttp.Handler
+
+		if !nethttp.HandlerAs(handler, &h) {
+			return nil
+		}
+
+		op, added, err := r.addOperation(method, route, h)
+		if added {
+			ops = append(ops, op)
+		}
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.operations = append(r.operations, ops...)
+	r.mu.Unlock()
+
+	s.Method(http.MethodGet, r.baseURL+"operations.html", http.HandlerFunc(r.serveOperations))
+
+	return nil
+}
+
+func (r *Repository) addOperation(method, route string, h *nethttp.Handler) (op Operation, added bool, err error) {
+	withInput, ok := h.UseCase().(usecase.HasInputPort)
+	if !ok || withInput.InputPort() == nil {
+		return op, false, nil
+	}
+
+	op = Operation{
+		Method:        method,
+		Pattern:       route,
+		Title:         method + " " + route,
+		SchemaName:    strings.Trim(nonAlphaDigit.ReplaceAllString(strings.ToLower(method+" "+route), "-"), "-"),
+		SuccessStatus: h.SuccessStatus,
+	}
+
+	if withTitle, ok := h.UseCase().(usecase.HasTitle); ok && withTitle.Title() != "" {
+		op.Title = withTitle.Title()
+	}
+
+	if op.SuccessStatus == 0 {
+		op.SuccessStatus = http.StatusOK
+
+		if withOutput, ok := h.UseCase().(usecase.HasOutputPort); ok && rest.OutputHasNoContent(withOutput.OutputPort()) {
+			op.SuccessStatus = http.StatusNoContent
+		}
+	}
+
+	for _, m := range pathParam.FindAllStringSubmatch(route, -1) {
+		op.PathParams = append(op.PathParams, m[1])
+	}
+
+	fs, err := r.reflect(withInput.InputPort(), op.SchemaName)
+	if err != nil {
+		return op, false, err
+	}
+
+	// Operations without JSON body are skipped.
+	if len(fs.Schema.Properties) == 0 {
+		return op, false, nil
+	}
+
+	// Schemas of operations that are already mounted are kept, so that MountOperations can be called again,
+	// e.g. after adding routes.
+	if existing := r.SchemaByName(op.SchemaName); existing != nil {
+		same, err := sameSchema(*existing, fs)
+		if err != nil || !same {
+			return op, false, fmt.Errorf("schema %s is already added", op.SchemaName)
+		}
+
+		return op, !r.hasOperation(op.Method, op.Pattern), nil
+	}
+
+	if err = r.addSchema(op.SchemaName, fs); err != nil {
+		return op, false, err
+	}
+
+	return op, true, nil
+}
+
+func (r *Repository) hasOperation(method, pattern string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, op := range r.operations {
+		if op.Method == method && op.Pattern == pattern {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameSchema compares JSON representations of form schemas.
+func sameSchema(a, b FormSchema) (bool, error) {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(ja, jb), nil
+}
+
+// Operations returns operations added with MountOperations.
+func (r *Repository) Operations() []Operation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Operation(nil), r.operations...)
+}
+
+var operationsTemplate = loadTemplate("operations_tmpl.html")
+
+func (r *Repository) serveOperations(w http.ResponseWriter, req *http.Request) {
+	visible := map[string]bool{}
+	for _, name := range r.NamesFor(req.Context()) {
+		visible[name] = true
+	}
+
+	var ops []Operation
+
+	for _, op := range r.Operations() {
+		if visible[op.SchemaName] {
+			ops = append(ops, op)
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	if err := operationsTemplate.Execute(buf, struct {
+		BaseURL    string
+		Operations []Operation
+	}{
+		BaseURL:    r.baseURL,
+		Operations: ops,
+	}); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return
+	}
+}
@@ -0,0 +1,92 @@
+package jsonform_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/openapi-go/openapi31"
+	"github.com/swaggest/rest/nethttp"
+	"github.com/swaggest/rest/web"
+	"github.com/swaggest/usecase"
+)
+
+func TestRepository_MountOperations(t *testing.T) {
+	s := web.NewService(openapi31.NewReflector())
+
+	createUser := usecase.NewInteractor(func(ctx context.Context, input User, output *struct{}) error {
+		return nil
+	})
+	createUser.SetTitle("Create User")
+
+	type updateUserInput struct {
+		ID int `path:"id"`
+		User
+	}
+
+	updateUser := usecase.NewInteractor(func(ctx context.Context, input updateUserInput, output *struct{}) error {
+		return nil
+	})
+	updateUser.SetTitle("Update User")
+
+	type getUserInput struct {
+		ID int `path:"id"`
+	}
+
+	getUser := usecase.NewInteractor(func(ctx context.Context, input getUserInput, output *User) error {
+		return nil
+	})
+
+	s.Post("/users", createUser, nethttp.SuccessStatus(http.StatusCreated))
+	s.Put("/user/{id}.json", updateUser)
+	s.Get("/user/{id}.json", getUser)
+
+	repo := jsonform.NewRepository(s.OpenAPIReflector().JSONSchemaReflector())
+	require.EqualError(t, repo.MountOperations(s), "repository is not mounted")
+
+	repo.Mount(s, "/json-form/")
+	require.NoError(t, repo.MountOperations(s))
+
+	assert.Equal(t, []jsonform.Operation{
+		{
+			Method: http.MethodPut, Pattern: "/user/{id}.json", Title: "Update User",
+			SchemaName: "put-user-id-json", SuccessStatus: http.StatusNoContent, PathParams: []string{"id"},
+		},
+		{
+			Method: http.MethodPost, Pattern: "/users", Title: "Create User",
+			SchemaName: "post-users", SuccessStatus: http.StatusCreated,
+		},
+	}, repo.Operations())
+
+	assert.Equal(t, []string{"post-users", "put-user-id-json"}, repo.Names())
+	assert.Equal(t, "firstName", repo.SchemaByName("put-user-id-json").Form[0].Key)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/json-form/operations.html", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Contains(t, rw.Body.String(), `<input type="hidden" name="submitUrl" value="/user/{id}.json"/>`)
+	assert.Contains(t, rw.Body.String(), `<input type="text" name="id" placeholder="id" required/>`)
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/json-form/put-user-id-json-schema.json", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	// Mounting again only adds operations of new routes.
+	require.NoError(t, repo.MountOperations(s))
+	assert.Len(t, repo.Operations(), 2)
+
+	s.Post("/admins", createUser, nethttp.SuccessStatus(http.StatusCreated))
+	require.NoError(t, repo.MountOperations(s))
+	assert.Len(t, repo.Operations(), 3)
+	assert.Equal(t, []string{"post-admins", "post-users", "put-user-id-json"}, repo.Names())
+
+	require.NoError(t, repo.AddNamed(struct {
+		Name string `json:"name"`
+	}{}, "patch-users"))
+	s.Patch("/users", createUser)
+	assert.EqualError(t, repo.MountOperations(s), "schema patch-users is already added")
+}
@@ -0,0 +1,435 @@
+package jsonform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/swaggest/usecase/status"
+)
+
+// SubmitMode defines how form value is submitted.
+type SubmitMode string
+
+// Submit modes.
+const (
+	// SubmitFull sends whole form value, it is the default.
+	SubmitFull = SubmitMode("full")
+
+	// SubmitMergePatch sends changed fields of form items as JSON Merge Patch (RFC 7396), see ApplyMergePatch.
+	SubmitMergePatch = SubmitMode("merge-patch")
+
+	// SubmitJSONPatch sends changes of form items as JSON Patch operations (RFC 6902), see ApplyJSONPatch.
+	SubmitJSONPatch = SubmitMode("json-patch")
+)
+
+// Content types of patch requests.
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+// ApplyMergePatch returns a copy of value with JSON Merge Patch (RFC 7396) applied.
+//
+// Value is patched in its JSON representation, fields that are not serialized to JSON have zero values in result.
+// Invalid patch results in status.InvalidArgument error.
+func ApplyMergePatch[T any](value T, patch []byte) (T, error) {
+	return applyPatch(value, func(doc interface{}) (interface{}, error) {
+		p, err := decodeJSONNumbers(patch)
+		if err != nil {
+			return nil, err
+		}
+
+		return mergePatch(doc, p), nil
+	})
+}
+
+// ApplyJSONPatch returns a copy of value with JSON Patch (RFC 6902) applied.
+//
+// Value is patched in its JSON representation, fields that are not serialized to JSON have zero values in result.
+// Invalid patch results in status.InvalidArgument error, failed "test" operation results in status.Aborted error.
+func ApplyJSONPatch[T any](value T, patch []byte) (T, error) {
+	return applyPatch(value, func(doc interface{}) (interface{}, error) {
+		var ops []jsonPatchOperation
+
+		if err := json.Unmarshal(patch, &ops); err != nil {
+			return nil, err
+		}
+
+		for i, op := range ops {
+			var err error
+
+			if doc, err = op.apply(doc); err != nil {
+				return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+			}
+		}
+
+		return doc, nil
+	})
+}
+
+func applyPatch[T any](value T, apply func(doc interface{}) (interface{}, error)) (T, error) {
+	var res T
+
+	j, err := json.Marshal(value)
+	if err != nil {
+		return res, err
+	}
+
+	doc, err := decodeJSONNumbers(j)
+	if err != nil {
+		return res, err
+	}
+
+	if doc, err = apply(doc); err != nil {
+		var se interface{ Status() status.Code }
+
+		if !errors.As(err, &se) {
+			err = status.Wrap(err, status.InvalidArgument)
+		}
+
+		return res, fmt.Errorf("applying patch: %w", err)
+	}
+
+	if j, err = json.Marshal(doc); err != nil {
+		return res, err
+	}
+
+	if err := json.Unmarshal(j, &res); err != nil {
+		return res, status.Wrap(fmt.Errorf("applying patch: %w", err), status.InvalidArgument)
+	}
+
+	return res, nil
+}
+
+func decodeJSONNumbers(data []byte) (interface{}, error) {
+	var v interface{}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{}, len(p))
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+
+	return t
+}
+
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (op jsonPatchOperation) apply(doc interface{}) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, errors.New("missing value")
+		}
+
+		value, err := decodeJSONNumbers(op.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Op == "add" {
+			return pointerAdd(doc, path, value)
+		}
+
+		current, err := pointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Op == "test" {
+			if !jsonEqual(current, value) {
+				return nil, status.Wrap(errors.New("test failed"), status.Aborted)
+			}
+
+			return doc, nil
+		}
+
+		if len(path) == 0 {
+			return value, nil
+		}
+
+		if doc, _, err = pointerRemove(doc, path); err != nil {
+			return nil, err
+		}
+
+		return pointerAdd(doc, path, value)
+	case "remove":
+		doc, _, err = pointerRemove(doc, path)
+
+		return doc, err
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Op == "move" {
+			if strings.HasPrefix(op.Path, op.From+"/") {
+				return nil, errors.New("can not move value into its child")
+			}
+
+			var value interface{}
+
+			if doc, value, err = pointerRemove(doc, from); err != nil {
+				return nil, err
+			}
+
+			return pointerAdd(doc, path, value)
+		}
+
+		value, err := pointerGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		// Copy is decoupled from source value.
+		j, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if value, err = decodeJSONNumbers(j); err != nil {
+			return nil, err
+		}
+
+		return pointerAdd(doc, path, value)
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// parsePointer splits JSON Pointer (RFC 6901) into reference tokens.
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(p, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", p)
+	}
+
+	tokens := strings.Split(p[1:], "/")
+
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// arrayIndex parses array index token, "-" refers to the end of array if allowed.
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return length, nil
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	if i > length || (i == length && !allowEnd) {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+
+	return i, nil
+}
+
+func pointerGet(doc interface{}, path []string) (interface{}, error) {
+	for _, t := range path {
+		switch d := doc.(type) {
+		case map[string]interface{}:
+			v, ok := d[t]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", t)
+			}
+
+			doc = v
+		case []interface{}:
+			i, err := arrayIndex(t, len(d), false)
+			if err != nil {
+				return nil, err
+			}
+
+			doc = d[i]
+		default:
+			return nil, fmt.Errorf("can not get %q of scalar value", t)
+		}
+	}
+
+	return doc, nil
+}
+
+// pointerUpdate applies change to the parent container of path and returns updated document.
+func pointerUpdate(doc interface{}, path []string, change func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return change(doc, path[0])
+	}
+
+	child, err := pointerGet(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+
+	if child, err = pointerUpdate(child, path[1:], change); err != nil {
+		return nil, err
+	}
+
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		d[path[0]] = child
+	case []interface{}:
+		i, err := arrayIndex(path[0], len(d), false)
+		if err != nil {
+			return nil, err
+		}
+
+		d[i] = child
+	}
+
+	return doc, nil
+}
+
+func pointerAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return pointerUpdate(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[token] = value
+
+			return p, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(p), true)
+			if err != nil {
+				return nil, err
+			}
+
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = value
+
+			return p, nil
+		default:
+			return nil, fmt.Errorf("can not add %q to scalar value", token)
+		}
+	})
+}
+
+func pointerRemove(doc interface{}, path []string) (res interface{}, removed interface{}, err error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("can not remove whole document")
+	}
+
+	res, err = pointerUpdate(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			v, ok := p[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+
+			removed = v
+
+			delete(p, token)
+
+			return p, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(p), false)
+			if err != nil {
+				return nil, err
+			}
+
+			removed = p[i]
+
+			return append(p[:i], p[i+1:]...), nil
+		default:
+			return nil, fmt.Errorf("can not remove %q of scalar value", token)
+		}
+	})
+
+	return res, removed, err
+}
+
+// jsonEqual compares decoded JSON values, numbers are compared by value.
+func jsonEqual(a, b interface{}) bool {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+
+		for k, v := range av {
+			if w, ok := bv[k]; !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+
+		return true
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+
+		af, aErr := av.Float64()
+		bf, bErr := bv.Float64()
+
+		return av == bv || (aErr == nil && bErr == nil && af == bf)
+	default:
+		return a == b
+	}
+}
@@ -0,0 +1,78 @@
+package jsonform_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/rest"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	u := UserWithNeighbors{
+		User:      User{FirstName: "John", LastName: "Doe", Age: 30},
+		Neighbors: []User{{FirstName: "Jane"}},
+	}
+
+	p, err := jsonform.ApplyMergePatch(u, []byte(`{"user":{"lastName":"Smith","age":null},"neighbors":[]}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "John", p.User.FirstName)
+	assert.Equal(t, "Smith", p.User.LastName)
+	assert.Equal(t, 0, p.User.Age)
+	assert.Empty(t, p.Neighbors)
+	assert.Equal(t, "Doe", u.User.LastName, "original value is not changed")
+
+	_, err = jsonform.ApplyMergePatch(u, []byte(`{"user":`))
+	code, _ := rest.Err(err)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	_, err = jsonform.ApplyMergePatch(u, []byte(`{"user":{"age":"old"}}`))
+	code, _ = rest.Err(err)
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	u := UserWithNeighbors{
+		User:      User{FirstName: "John", LastName: "Doe", Age: 30},
+		Neighbors: []User{{FirstName: "Jane"}, {FirstName: "Jim"}},
+	}
+
+	p, err := jsonform.ApplyJSONPatch(u, []byte(`[
+	  {"op":"test","path":"/user/age","value":30.0},
+	  {"op":"replace","path":"/user/lastName","value":"Smith"},
+	  {"op":"add","path":"/neighbors/0","value":{"firstName":"Jack"}},
+	  {"op":"remove","path":"/neighbors/2"},
+	  {"op":"copy","from":"/neighbors/1","path":"/neighbors/-"},
+	  {"op":"move","from":"/user/firstName","path":"/neighbors/0/lastName"}
+	]`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "", p.User.FirstName)
+	assert.Equal(t, "Smith", p.User.LastName)
+	require.Len(t, p.Neighbors, 3)
+	assert.Equal(t, User{FirstName: "Jack", LastName: "John"}, p.Neighbors[0])
+	assert.Equal(t, "Jane", p.Neighbors[1].FirstName)
+	assert.Equal(t, "Jane", p.Neighbors[2].FirstName)
+
+	_, err = jsonform.ApplyJSONPatch(u, []byte(`[{"op":"test","path":"/user/age","value":31}]`))
+	code, _ := rest.Err(err)
+	assert.Equal(t, http.StatusConflict, code)
+	assert.EqualError(t, err, "applying patch: operation 0 (test /user/age): aborted: test failed")
+
+	for _, patch := range []string{
+		`{}`,
+		`[{"op":"remove","path":"/user/unknown"}]`,
+		`[{"op":"add","path":"/neighbors/5","value":{}}]`,
+		`[{"op":"add","path":"/neighbors/01","value":{}}]`,
+		`[{"op":"replace","path":"/user/age"}]`,
+		`[{"op":"move","from":"/user","path":"/user/child"}]`,
+		`[{"op":"unknown","path":""}]`,
+	} {
+		_, err = jsonform.ApplyJSONPatch(u, []byte(patch))
+		code, _ = rest.Err(err)
+		assert.Equal(t, http.StatusBadRequest, code, patch)
+	}
+}
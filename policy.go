@@ -0,0 +1,482 @@
+package jsonform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+	"github.com/swaggest/usecase/status"
+)
+
+// FieldAccess defines how a form item is exposed.
+type FieldAccess int
+
+// Field access levels, from the least restrictive.
+const (
+	// FieldEditable keeps form item as is.
+	FieldEditable FieldAccess = iota
+
+	// FieldReadOnly renders form item as read-only.
+	FieldReadOnly
+
+	// FieldHidden renders form item as hidden input.
+	FieldHidden
+
+	// FieldRemoved removes form item and its schema property.
+	FieldRemoved
+)
+
+// Policy returns access level of a form item of a named schema, for example based on request principal from context.
+type Policy func(ctx context.Context, schemaName string, item FormItem) FieldAccess
+
+// AddPolicy registers field-level policy, the most restrictive access of all policies is applied.
+func (r *Repository) AddPolicy(p Policy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.policies = append(r.policies, p)
+}
+
+// SchemaFor returns schema by name with authorization and field policies applied in context.
+func (r *Repository) SchemaFor(ctx context.Context, name string) (*FormSchema, error) {
+	// Authorization is checked first, so that denied names can not be probed for existence.
+	if err := r.authorize(ctx, name); err != nil {
+		return nil, err
+	}
+
+	fs := r.SchemaByName(name)
+	if fs == nil {
+		return nil, status.Wrap(fmt.Errorf("unknown schema %s", name), status.NotFound)
+	}
+
+	r.mu.Lock()
+	policies := r.policies
+	r.mu.Unlock()
+
+	if len(policies) == 0 {
+		return fs, nil
+	}
+
+	pa := policyApplier{name: name, policies: policies}
+	form := pa.apply(ctx, fs.Form)
+
+	if len(pa.removed) == 0 {
+		return &FormSchema{Form: form, Schema: fs.Schema}, nil
+	}
+
+	schema := copySchema(fs.Schema)
+
+	for _, key := range pa.removed {
+		removeProperty(&schema, key)
+	}
+
+	return &FormSchema{Form: form, Schema: schema}, nil
+}
+
+// CheckLocked returns error if submitted value changes fields that are locked by policies in context.
+//
+// Original and submitted values can be Go values or JSON documents as []byte or json.RawMessage.
+// Fields removed by policies are only checked if they are present in submitted JSON.
+func (r *Repository) CheckLocked(ctx context.Context, name string, original, submitted interface{}) error {
+	fs := r.SchemaByName(name)
+	if fs == nil {
+		return status.Wrap(fmt.Errorf("unknown schema %s", name), status.NotFound)
+	}
+
+	r.mu.Lock()
+	policies := r.policies
+	r.mu.Unlock()
+
+	if len(policies) == 0 {
+		return nil
+	}
+
+	pa := policyApplier{name: name, policies: policies}
+	pa.apply(ctx, fs.Form)
+
+	if len(pa.locked) == 0 && len(pa.removed) == 0 {
+		return nil
+	}
+
+	orig, err := toJSONValue(original)
+	if err != nil {
+		return err
+	}
+
+	sub, err := toJSONValue(submitted)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range pa.locked {
+		if !reflect.DeepEqual(valuesAt(orig, key), valuesAt(sub, key)) {
+			return status.Wrap(fmt.Errorf("field %s is locked", key), status.PermissionDenied)
+		}
+	}
+
+	for _, key := range pa.removed {
+		subValues := valuesAt(sub, key)
+
+		if len(subValues) > 0 && !reflect.DeepEqual(valuesAt(orig, key), subValues) {
+			return status.Wrap(fmt.Errorf("field %s is locked", key), status.PermissionDenied)
+		}
+	}
+
+	return nil
+}
+
+// ValueFor returns value without fields that are removed by policies in context, see FieldRemoved.
+//
+// Value is returned as is if no fields are removed, otherwise it is converted to a generic JSON value.
+// Original value can be a Go value or a JSON document as []byte or json.RawMessage.
+func (r *Repository) ValueFor(ctx context.Context, name string, value interface{}) (interface{}, error) {
+	fs := r.SchemaByName(name)
+	if fs == nil {
+		return nil, status.Wrap(fmt.Errorf("unknown schema %s", name), status.NotFound)
+	}
+
+	r.mu.Lock()
+	policies := r.policies
+	r.mu.Unlock()
+
+	if len(policies) == 0 {
+		return value, nil
+	}
+
+	pa := policyApplier{name: name, policies: policies}
+	pa.apply(ctx, fs.Form)
+
+	if len(pa.removed) == 0 {
+		return value, nil
+	}
+
+	v, err := toJSONValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range pa.removed {
+		removeValue(v, strings.Split(key, "."))
+	}
+
+	return v, nil
+}
+
+// withRemoved returns submitted value with fields removed by policies in context copied from current value,
+// so that fields that are not exposed in form are not lost on update.
+func (r *Repository) withRemoved(ctx context.Context, name string, current, submitted interface{}) (interface{}, error) {
+	fs := r.SchemaByName(name)
+	if fs == nil {
+		return nil, status.Wrap(fmt.Errorf("unknown schema %s", name), status.NotFound)
+	}
+
+	r.mu.Lock()
+	policies := r.policies
+	r.mu.Unlock()
+
+	pa := policyApplier{name: name, policies: policies}
+	pa.apply(ctx, fs.Form)
+
+	if len(pa.removed) == 0 {
+		return submitted, nil
+	}
+
+	cur, err := toJSONValue(current)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := toJSONValue(submitted)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range pa.removed {
+		restoreValue(sub, cur, strings.Split(key, "."))
+	}
+
+	return sub, nil
+}
+
+type policyApplier struct {
+	name     string
+	policies []Policy
+
+	locked  []string
+	removed []string
+}
+
+func (pa *policyApplier) access(ctx context.Context, item FormItem) FieldAccess {
+	access := FieldEditable
+
+	for _, p := range pa.policies {
+		if a := p(ctx, pa.name, item); a > access {
+			access = a
+		}
+	}
+
+	return access
+}
+
+func (pa *policyApplier) apply(ctx context.Context, items []FormItem) []FormItem {
+	res := make([]FormItem, 0, len(items))
+
+	for _, item := range items {
+		if item.Key != "" {
+			switch pa.access(ctx, item) {
+			case FieldEditable:
+			case FieldReadOnly:
+				item.ReadOnly = true
+
+				pa.locked = append(pa.locked, item.Key)
+			case FieldHidden:
+				item.FormType = "hidden"
+				item.Items = nil
+
+				pa.locked = append(pa.locked, item.Key)
+			case FieldRemoved:
+				pa.removed = append(pa.removed, item.Key)
+
+				continue
+			}
+		}
+
+		if len(item.Items) > 0 {
+			item.Items = pa.apply(ctx, item.Items)
+		}
+
+		res = append(res, item)
+	}
+
+	return res
+}
+
+// copySchema copies properties, items and required lists that are changed by removeProperty.
+//
+// JSON round trip is not used, because Draft 3 "required": true of properties does not unmarshal.
+func copySchema(s jsonschema.Schema) jsonschema.Schema {
+	if s.Properties != nil {
+		props := make(map[string]jsonschema.SchemaOrBool, len(s.Properties))
+
+		for name, prop := range s.Properties {
+			if prop.TypeObject != nil {
+				c := copySchema(*prop.TypeObject)
+				prop.TypeObject = &c
+			}
+
+			props[name] = prop
+		}
+
+		s.Properties = props
+	}
+
+	if s.Items != nil && s.Items.SchemaOrBool != nil && s.Items.SchemaOrBool.TypeObject != nil {
+		c := copySchema(*s.Items.SchemaOrBool.TypeObject)
+		items := *s.Items
+		items.SchemaOrBool = &jsonschema.SchemaOrBool{TypeObject: &c}
+		s.Items = &items
+	}
+
+	s.Required = append([]string(nil), s.Required...)
+
+	return s
+}
+
+// removeProperty removes schema property by form item key.
+func removeProperty(s *jsonschema.Schema, key string) {
+	parts := strings.Split(key, ".")
+
+	for i, part := range parts {
+		name, arrays := keyPart(part)
+
+		if i == len(parts)-1 {
+			delete(s.Properties, name)
+
+			required := s.Required[:0]
+
+			for _, r := range s.Required {
+				if r != name {
+					required = append(required, r)
+				}
+			}
+
+			s.Required = required
+
+			return
+		}
+
+		prop, ok := s.Properties[name]
+		if !ok || prop.TypeObject == nil {
+			return
+		}
+
+		s = prop.TypeObject
+
+		for ; arrays > 0; arrays-- {
+			if s.Items == nil || s.Items.SchemaOrBool == nil || s.Items.SchemaOrBool.TypeObject == nil {
+				return
+			}
+
+			s = s.Items.SchemaOrBool.TypeObject
+		}
+	}
+}
+
+// removeValue removes field of generic JSON value by form item key parts.
+func removeValue(v interface{}, parts []string) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	name, arrays := keyPart(parts[0])
+
+	if len(parts) == 1 {
+		delete(m, name)
+
+		return
+	}
+
+	values := []interface{}{m[name]}
+
+	for ; arrays > 0; arrays-- {
+		items := make([]interface{}, 0, len(values))
+
+		for _, val := range values {
+			if a, ok := val.([]interface{}); ok {
+				items = append(items, a...)
+			}
+		}
+
+		values = items
+	}
+
+	for _, val := range values {
+		removeValue(val, parts[1:])
+	}
+}
+
+// restoreValue copies field of generic JSON value src to dst by form item key parts,
+// array items are matched by index.
+func restoreValue(dst, src interface{}, parts []string) {
+	d, ok := dst.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	s, ok := src.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	name, arrays := keyPart(parts[0])
+	sv, found := s[name]
+
+	if len(parts) == 1 {
+		if found {
+			d[name] = sv
+		} else {
+			delete(d, name)
+		}
+
+		return
+	}
+
+	dstValues, srcValues := []interface{}{d[name]}, []interface{}{sv}
+
+	for ; arrays > 0; arrays-- {
+		var nextDst, nextSrc []interface{}
+
+		for i := range dstValues {
+			da, _ := dstValues[i].([]interface{})
+			sa, _ := srcValues[i].([]interface{})
+
+			for j := 0; j < len(da) && j < len(sa); j++ {
+				nextDst = append(nextDst, da[j])
+				nextSrc = append(nextSrc, sa[j])
+			}
+		}
+
+		dstValues, srcValues = nextDst, nextSrc
+	}
+
+	for i := range dstValues {
+		restoreValue(dstValues[i], srcValues[i], parts[1:])
+	}
+}
+
+// keyPart returns property name and array depth of a form item key part, e.g. "neighbors[]".
+func keyPart(part string) (name string, arrays int) {
+	name = part
+
+	for strings.HasSuffix(name, "[]") {
+		name = strings.TrimSuffix(name, "[]")
+		arrays++
+	}
+
+	return name, arrays
+}
+
+// toJSONValue converts value to a generic JSON value.
+func toJSONValue(v interface{}) (interface{}, error) {
+	var (
+		j   []byte
+		err error
+	)
+
+	switch vv := v.(type) {
+	case []byte:
+		j = vv
+	case json.RawMessage:
+		j = vv
+	default:
+		if j, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+
+	var res interface{}
+
+	if err := json.Unmarshal(j, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// valuesAt returns values of generic JSON value by form item key, array items are denoted with "[]".
+func valuesAt(v interface{}, key string) []interface{} {
+	values := []interface{}{v}
+
+	for _, part := range strings.Split(key, ".") {
+		name, arrays := keyPart(part)
+		next := make([]interface{}, 0, len(values))
+
+		for _, val := range values {
+			if m, ok := val.(map[string]interface{}); ok {
+				if fv, ok := m[name]; ok {
+					next = append(next, fv)
+				}
+			}
+		}
+
+		for ; arrays > 0; arrays-- {
+			items := make([]interface{}, 0, len(next))
+
+			for _, val := range next {
+				if a, ok := val.([]interface{}); ok {
+					items = append(items, a...)
+				}
+			}
+
+			next = items
+		}
+
+		values = next
+	}
+
+	return values
+}
@@ -0,0 +1,188 @@
+package jsonform_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/assertjson"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/jsonschema-go"
+	"github.com/swaggest/usecase/status"
+)
+
+func TestRepository_SchemaFor(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+	require.NoError(t, repo.AddNamed(UserWithNeighbors{}, "user"))
+
+	repo.AddPolicy(func(ctx context.Context, schemaName string, item jsonform.FormItem) jsonform.FieldAccess {
+		if ctx.Value(roleCtxKey{}) == "admin" {
+			return jsonform.FieldEditable
+		}
+
+		switch item.Key {
+		case "user.firstName":
+			return jsonform.FieldReadOnly
+		case "user.status":
+			return jsonform.FieldHidden
+		case "user.bio", "neighbors[].age":
+			return jsonform.FieldRemoved
+		}
+
+		return jsonform.FieldEditable
+	})
+
+	admin := context.WithValue(context.Background(), roleCtxKey{}, "admin")
+
+	fs, err := repo.SchemaFor(admin, "user")
+	require.NoError(t, err)
+	assert.Equal(t, repo.SchemaByName("user"), fs)
+
+	fs, err = repo.SchemaFor(context.Background(), "user")
+	require.NoError(t, err)
+
+	assertjson.EqMarshal(t, `[
+	  {"key":"user.firstName","readonly":true},{"key":"user.lastName"},
+	  {"key":"user.locale"},{"key":"user.age"},{"key":"user.status","type":"hidden"},
+	  {
+		"key":"neighbors","type":"array",
+		"items":[
+		  {
+			"type":"section",
+			"items":[
+			  {"key":"neighbors[].firstName"},{"key":"neighbors[].lastName"},
+			  {"key":"neighbors[].locale"},{"key":"neighbors[].status"},
+			  {"key":"neighbors[].bio","type":"textarea"}
+			]
+		  }
+		]
+	  }
+	]`, fs.Form)
+
+	assert.NotContains(t, fs.Schema.Properties["user"].TypeObject.Properties, "bio")
+	assert.Contains(t, fs.Schema.Properties["user"].TypeObject.Properties, "firstName")
+	assert.NotContains(t, fs.Schema.Properties["neighbors"].TypeObject.Items.SchemaOrBool.TypeObject.Properties, "age")
+
+	// Registered schema is not affected.
+	assert.Contains(t, repo.SchemaByName("user").Schema.Properties["user"].TypeObject.Properties, "bio")
+
+	_, err = repo.SchemaFor(context.Background(), "unknown")
+	assert.EqualError(t, err, "not found: unknown schema unknown")
+
+	orig := UserWithNeighbors{
+		User:      User{FirstName: "John", LastName: "Doe", Bio: "Secret"},
+		Neighbors: []User{{FirstName: "Jane", Age: 30}},
+	}
+
+	changed := orig
+	changed.User.LastName = "Smith"
+	require.NoError(t, repo.CheckLocked(context.Background(), "user", orig, changed))
+
+	changed.User.FirstName = "Jim"
+	assert.EqualError(t, repo.CheckLocked(context.Background(), "user", orig, changed),
+		"permission denied: field user.firstName is locked")
+	require.NoError(t, repo.CheckLocked(admin, "user", orig, changed))
+
+	// Removed fields are only checked when present.
+	require.NoError(t, repo.CheckLocked(context.Background(), "user", orig,
+		[]byte(`{"user":{"firstName":"John","status":""},"neighbors":[{"firstName":"Jane"}]}`)))
+	assert.EqualError(t, repo.CheckLocked(context.Background(), "user", orig,
+		[]byte(`{"user":{"firstName":"John","status":""},"neighbors":[{"firstName":"Jane","age":31}]}`)),
+		"permission denied: field neighbors[].age is locked")
+}
+
+func TestRepository_SchemaFor_requiredProperties(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+	require.NoError(t, repo.AddNamed(User{}, "user"))
+
+	repo.AddPolicy(func(ctx context.Context, schemaName string, item jsonform.FormItem) jsonform.FieldAccess {
+		if item.Key == "bio" {
+			return jsonform.FieldRemoved
+		}
+
+		return jsonform.FieldEditable
+	})
+
+	fs, err := repo.SchemaFor(context.Background(), "user")
+	require.NoError(t, err)
+	assert.NotContains(t, fs.Schema.Properties, "bio")
+	assert.Equal(t, true, fs.Schema.Properties["firstName"].TypeObject.ExtraProperties["required"])
+	assert.Contains(t, repo.SchemaByName("user").Schema.Properties, "bio")
+}
+
+func TestRepository_RenderContext_removedFields(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+	require.NoError(t, repo.AddNamed(UserWithNeighbors{}, "user"))
+
+	repo.AddPolicy(func(ctx context.Context, schemaName string, item jsonform.FormItem) jsonform.FieldAccess {
+		if item.Key == "user.bio" || item.Key == "neighbors[].age" {
+			return jsonform.FieldRemoved
+		}
+
+		return jsonform.FieldEditable
+	})
+
+	value := UserWithNeighbors{
+		User:      User{FirstName: "John", Bio: "Secret bio"},
+		Neighbors: []User{{FirstName: "Jane", Age: 42}},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.RenderContext(context.Background(), buf, jsonform.Page{}, jsonform.Form{Value: value}))
+
+	assert.Contains(t, buf.String(), `"firstName":"John"`)
+	assert.Contains(t, buf.String(), `"firstName":"Jane"`)
+	assert.NotContains(t, buf.String(), `Secret bio`)
+	assert.NotContains(t, buf.String(), `"age":42`)
+
+	v, err := repo.ValueFor(context.Background(), "user", value)
+	require.NoError(t, err)
+	assertjson.EqMarshal(t, `{
+	  "user":{"firstName":"John","lastName":"","locale":"","age":0,"status":""},
+	  "neighbors":[{"firstName":"Jane","lastName":"","locale":"","status":"","bio":""}]
+	}`, v)
+
+	_, err = repo.ValueFor(context.Background(), "unknown", value)
+	assert.EqualError(t, err, "not found: unknown schema unknown")
+}
+
+func TestRepository_Render_authorize(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+	repo.Authorize = func(ctx context.Context, name string) error {
+		if ctx.Value(roleCtxKey{}) != "admin" {
+			return status.PermissionDenied
+		}
+
+		return nil
+	}
+
+	repo.AddPolicy(func(ctx context.Context, schemaName string, item jsonform.FormItem) jsonform.FieldAccess {
+		if item.Key == "bio" {
+			return jsonform.FieldRemoved
+		}
+
+		return jsonform.FieldEditable
+	})
+
+	value := User{FirstName: "John", Bio: "Secret bio"}
+
+	// Rendering without context does not authorize, but applies policies.
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.Render(buf, jsonform.Page{}, jsonform.Form{Value: value}))
+	assert.Contains(t, buf.String(), `"firstName":"John"`)
+	assert.NotContains(t, buf.String(), `Secret bio`)
+
+	_, err := repo.RenderFragment(jsonform.Page{}, jsonform.Form{Value: value})
+	require.NoError(t, err)
+	require.NoError(t, repo.RenderView(buf, jsonform.Page{}, value))
+	require.NoError(t, repo.RenderList(buf, jsonform.Page{}, jsonform.List{Items: []User{value}}))
+	require.NoError(t, repo.RenderDiff(buf, jsonform.Page{}, value, value))
+
+	err = repo.RenderContext(context.Background(), buf, jsonform.Page{}, jsonform.Form{Value: value})
+	assert.ErrorIs(t, err, status.PermissionDenied)
+
+	admin := context.WithValue(context.Background(), roleCtxKey{}, "admin")
+	require.NoError(t, repo.RenderContext(admin, buf, jsonform.Page{}, jsonform.Form{Value: value}))
+}
@@ -0,0 +1,324 @@
+package jsonform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+)
+
+// orderedMap is a JSON object that keeps order of keys.
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: make(map[string]interface{})}
+}
+
+func (m *orderedMap) get(key string) (interface{}, bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	v, ok := m.values[key]
+
+	return v, ok
+}
+
+func (m *orderedMap) set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+
+	m.values[key] = value
+}
+
+func (m *orderedMap) delete(key string) {
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+
+	delete(m.values, key)
+
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i:i], m.keys[i+1:]...)
+
+			break
+		}
+	}
+}
+
+func (m *orderedMap) object(key string) *orderedMap {
+	v, _ := m.get(key)
+	o, _ := v.(*orderedMap)
+
+	return o
+}
+
+func (m *orderedMap) str(key string) string {
+	v, _ := m.get(key)
+	s, _ := v.(string)
+
+	return s
+}
+
+// MarshalJSON encodes JSON object with keys in original order.
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	buf.WriteByte('{')
+
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		kj, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+
+		vj, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(kj)
+		buf.WriteByte(':')
+		buf.Write(vj)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// AddSchema registers form schema of JSON Schema.
+//
+// Form items are created from schema properties with the same keys as for Go values,
+// form item options can be defined with "x-jsonform-" prefixed extensions of property schema,
+// e.g. {"type":"string","x-jsonform-type":"textarea"}.
+// Properties are ordered by name, use AddSchemaJSON to keep order of JSON document.
+func (r *Repository) AddSchema(name string, schema jsonschema.Schema) error {
+	j, err := json.Marshal(schema)
+	if err != nil {
+		return err
+	}
+
+	return r.AddSchemaJSON(name, j)
+}
+
+// AddSchemaJSON registers form schema of JSON Schema document, see AddSchema.
+func (r *Repository) AddSchemaJSON(name string, schema []byte) error {
+	v, err := decodeOrderedJSON(schema)
+	if err != nil {
+		return fmt.Errorf("decoding %s schema: %w", name, err)
+	}
+
+	raw, ok := v.(*orderedMap)
+	if !ok {
+		return fmt.Errorf("decoding %s schema: object expected", name)
+	}
+
+	return r.addRawSchema(name, raw)
+}
+
+func (r *Repository) addRawSchema(name string, schema *orderedMap) error {
+	fs, err := formSchemaFromRaw(name, schema)
+	if err != nil {
+		return err
+	}
+
+	return r.addSchema(name, fs)
+}
+
+// decodeOrderedJSON decodes JSON value with ordered objects.
+func decodeOrderedJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	v, err := decodeOrderedValue(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+
+	return v, nil
+}
+
+func decodeOrderedValue(dec *json.Decoder) (interface{}, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case json.Delim('{'):
+		m := newOrderedMap()
+
+		for dec.More() {
+			kt, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			k, _ := kt.(string)
+
+			v, err := decodeOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			m.set(k, v)
+		}
+
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+
+		return m, nil
+	case json.Delim('['):
+		items := make([]interface{}, 0)
+
+		for dec.More() {
+			v, err := decodeOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, v)
+		}
+
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+
+		return items, nil
+	default:
+		return t, nil
+	}
+}
+
+// formSchemaFromRaw creates form schema from JSON Schema document.
+func formSchemaFromRaw(name string, raw *orderedMap) (fs FormSchema, err error) {
+	j, err := json.Marshal(raw)
+	if err != nil {
+		return fs, err
+	}
+
+	if err := json.Unmarshal(j, &fs.Schema); err != nil {
+		return fs, fmt.Errorf("decoding %s schema: %w", name, err)
+	}
+
+	draft3(&fs.Schema)
+
+	fs.Form, err = schemaFormItems(raw, "")
+	if err != nil {
+		return fs, fmt.Errorf("%s form: %w", name, err)
+	}
+
+	return fs, nil
+}
+
+// schemaFormItems creates form items of object schema properties, keys follow the same rules as for Go values.
+func schemaFormItems(schema *orderedMap, prefix string) ([]FormItem, error) {
+	var items []FormItem
+
+	props := schema.object("properties")
+	if props == nil {
+		return nil, nil
+	}
+
+	for _, name := range props.keys {
+		prop := props.object(name)
+		if prop == nil {
+			continue
+		}
+
+		key := prefix + name
+
+		if prop.object("properties") != nil {
+			objItems, err := schemaFormItems(prop, key+".")
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, objItems...)
+
+			continue
+		}
+
+		fi, err := extensionFormItem(prop)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+
+		fi.Key = key
+
+		if itemSchema := prop.object("items"); itemSchema != nil && itemSchema.object("properties") != nil {
+			sectionItems, err := schemaFormItems(itemSchema, key+"[].")
+			if err != nil {
+				return nil, err
+			}
+
+			fi.FormType = "array"
+			fi.Items = []FormItem{{
+				FormType: "section",
+				Items:    sectionItems,
+			}}
+		}
+
+		items = append(items, fi)
+	}
+
+	return items, nil
+}
+
+// extensionFormItem creates form item from "x-jsonform-" prefixed extensions of property schema,
+// extension names match JSON keys of FormItem, e.g. "x-jsonform-type".
+func extensionFormItem(prop *orderedMap) (FormItem, error) {
+	var fi FormItem
+
+	ext := newOrderedMap()
+
+	for _, k := range prop.keys {
+		if name := strings.TrimPrefix(k, "x-jsonform-"); name != k && name != "key" && name != "items" {
+			ext.set(name, prop.values[k])
+		}
+	}
+
+	if len(ext.keys) == 0 {
+		return fi, nil
+	}
+
+	j, err := json.Marshal(ext)
+	if err != nil {
+		return fi, err
+	}
+
+	if err := json.Unmarshal(j, &fi); err != nil {
+		return fi, fmt.Errorf("invalid x-jsonform extension: %w", err)
+	}
+
+	return fi, nil
+}
+
+// draft3 moves required properties to property schemas for compliance with Draft 3.
+func draft3(schema *jsonschema.Schema) {
+	for _, name := range schema.Required {
+		if prop, ok := schema.Properties[name]; ok && prop.TypeObject != nil {
+			prop.TypeObject.WithExtraPropertiesItem("required", true)
+		}
+	}
+
+	schema.Required = nil
+}
@@ -0,0 +1,60 @@
+package jsonform_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/assertjson"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/jsonschema-go"
+)
+
+func TestRepository_AddSchemaJSON(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	require.NoError(t, repo.AddSchemaJSON("feedback", []byte(`{
+	  "type":"object",
+	  "properties":{
+		"email":{"type":"string","format":"email","x-jsonform-placeholder":"you@example.com"},
+		"message":{"type":"string","x-jsonform-type":"textarea","x-jsonform-key":"ignored"},
+		"author":{"type":"object","properties":{"name":{"type":"string"},"age":{"type":"integer"}}},
+		"links":{"type":"array","items":{"type":"object","properties":{"url":{"type":"string"}}}},
+		"tags":{"type":"array","items":{"type":"string"}}
+	  },
+	  "required":["email"]
+	}`)))
+
+	fs := repo.SchemaByName("feedback")
+	require.NotNil(t, fs)
+
+	assertjson.EqMarshal(t, `[
+	  {"key":"email","placeholder":"you@example.com"},
+	  {"key":"message","type":"textarea"},
+	  {"key":"author.name"},{"key":"author.age"},
+	  {"key":"links","type":"array","items":[{"type":"section","items":[{"key":"links[].url"}]}]},
+	  {"key":"tags"}
+	]`, fs.Form)
+
+	assert.Equal(t, true, fs.Schema.Properties["email"].TypeObject.ExtraProperties["required"])
+
+	assert.EqualError(t, repo.AddSchemaJSON("feedback", []byte(`{"type":"object"}`)),
+		"schema feedback is already added")
+	assert.EqualError(t, repo.AddSchemaJSON("bad", []byte(`[]`)),
+		"decoding bad schema: object expected")
+	assert.EqualError(t, repo.AddSchemaJSON("bad", []byte(`{"properties":{"a":{"x-jsonform-readonly":"yes"}}}`)),
+		"bad form: a: invalid x-jsonform extension: json: cannot unmarshal string into Go struct field FormItem.readonly of type bool")
+}
+
+func TestRepository_AddSchema_jsonSchema(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	var s jsonschema.Schema
+
+	s.AddType(jsonschema.Object)
+	s.WithPropertiesItem("name", (&jsonschema.Schema{}).WithType(jsonschema.String.Type()).
+		WithExtraPropertiesItem("x-jsonform-htmlClass", "wide").ToSchemaOrBool())
+
+	require.NoError(t, repo.AddSchema("named", s))
+	assertjson.EqMarshal(t, `[{"key":"name","htmlClass":"wide"}]`, repo.SchemaByName("named").Form)
+}
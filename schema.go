@@ -1,9 +1,11 @@
 package jsonform
 
 import (
+	"context"
 	"fmt"
 	"path"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 
@@ -47,13 +49,22 @@ type Repository struct {
 	// Strict requires all schemas to be added in advance.
 	Strict bool
 
+	// Authorize is an optional hook to check access to a schema by name, it denies access by returning error.
+	//
+	// It is applied by GetSchema and NamesFor. Unknown names are also passed to Authorize,
+	// denied names get the same error whether the schema exists or not.
+	Authorize func(ctx context.Context, name string) error
+
 	reflector *jsonschema.Reflector
 
 	mu            sync.Mutex
 	schemasByName map[string]FormSchema
 	namesByType   map[reflect.Type]string
+	policies      []Policy
+	operations    []Operation
 
-	baseURL string
+	baseURL   string
+	fragments uint32
 }
 
 // NewRepository creates schema repository.
@@ -111,6 +122,20 @@ func (r *Repository) AddNamed(value interface{}, name string) error {
 	return nil
 }
 
+// addSchema registers schema by name.
+func (r *Repository) addSchema(name string, fs FormSchema) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.schemasByName[name]; ok {
+		return fmt.Errorf("schema %s is already added", name)
+	}
+
+	r.schemasByName[name] = fs
+
+	return nil
+}
+
 func (r *Repository) reflect(value interface{}, name string) (fs FormSchema, err error) {
 	itemsSection := map[string]*FormItem{}
 
@@ -152,13 +177,7 @@ func (r *Repository) reflect(value interface{}, name string) (fs FormSchema, err
 		return fs, fmt.Errorf("reflecting %s schema: %w", name, err)
 	}
 
-	for _, name := range schema.Required { // Complying with Draft 3.
-		if prop, ok := schema.Properties[name]; ok {
-			prop.TypeObject.WithExtraPropertiesItem("required", true)
-		}
-	}
-
-	schema.Required = nil
+	draft3(&schema)
 
 	fs.Schema = schema
 
@@ -186,11 +205,53 @@ func (r *Repository) SchemaByName(name string) *FormSchema {
 
 // Names returns names of added schemas.
 func (r *Repository) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	names := make([]string, 0, len(r.schemasByName))
 
 	for name := range r.schemasByName {
 		names = append(names, name)
 	}
 
+	sort.Strings(names)
+
 	return names
 }
+
+// NamesFor returns names of added schemas that are visible in context, see Repository.Authorize.
+func (r *Repository) NamesFor(ctx context.Context) []string {
+	names := r.Names()
+
+	if r.Authorize == nil {
+		return names
+	}
+
+	visible := names[:0]
+
+	for _, name := range names {
+		if r.Authorize(ctx, name) == nil {
+			visible = append(visible, name)
+		}
+	}
+
+	return visible
+}
+
+// serverSideKey marks context of rendering methods without request context.
+type serverSideKey struct{}
+
+// serverSide returns context for rendering without request context,
+// Repository.Authorize is skipped in it, field policies are applied as for an anonymous caller.
+func serverSide() context.Context {
+	return context.WithValue(context.Background(), serverSideKey{}, true)
+}
+
+// authorize checks access to schema.
+func (r *Repository) authorize(ctx context.Context, name string) error {
+	if r.Authorize == nil || ctx.Value(serverSideKey{}) != nil {
+		return nil
+	}
+
+	return r.Authorize(ctx, name)
+}
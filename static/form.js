@@ -58,9 +58,64 @@
         this.valueUrl = '';
         this.value = undefined;
 
+        /**
+         * @type {String|null} - ETag of value loaded from valueUrl, sent in If-Match header on submit.
+         */
+        this.etag = null;
+
         this.submitUrl = '';
         this.submitMethod = 'POST';
         this.successStatus = 200;
+
+        /**
+         * @type {String} - full, merge-patch or json-patch.
+         */
+        this.submitMode = 'full';
+
+        /**
+         * @type {String} - json, form-urlencoded or multipart.
+         */
+        this.submitEncoding = 'json';
+
+        /**
+         * @type {Object.<String, String>} - additional request headers.
+         */
+        this.headers = {};
+
+        /**
+         * @type {HeadersCallback|null}
+         */
+        this.headersProvider = null;
+
+        /**
+         * @type {String} - credentials mode of requests, "include" enables credentials for cross-origin requests,
+         * "omit" sends requests without cookies.
+         */
+        this.credentials = '';
+
+        /**
+         * @type {Boolean} - keep draft values in localStorage.
+         */
+        this.autosave = false;
+
+        this.name = '';
+
+        /**
+         * @type {String} - URL template to navigate to after successful submit, filled from response JSON.
+         */
+        this.successRedirect = '';
+
+        /**
+         * @type {Object|undefined} - form values to detect unsaved changes.
+         */
+        this.baseline = undefined;
+
+        /**
+         * @type {Object.<String, String>} - initial states of fields by name.
+         */
+        this.initialFields = {};
+
+        instances.push(this);
     }
 
     /**
@@ -74,11 +129,19 @@
      * @property {String} submitUrl - URL to submit form.
      * @property {String} submitMethod - HTTP method to use on form submit.
      * @property {Number} successStatus - Success HTTP status code to expect on submit.
+     * @property {String} submitMode - Submit mode: full (default), merge-patch or json-patch.
+     * @property {String} submitEncoding - Submit encoding: json (default), form-urlencoded or multipart.
+     * @property {Boolean} autosave - Keep draft values in localStorage and offer to restore them.
+     * @property {String} successRedirect - URL template to navigate to after successful submit, e.g. /user/{id}.
      * @property {RawCallback} onSuccess - Callback for successful response.
      * @property {RawCallback} onFail - Callback for failed response.
      * @property {HTMLCallback} onError - Callback for error.
      * @property {JSONCallback} onBeforeSubmit - Callback for submittable form data.
      * @property {RawCallback} onRequestFinished - Callback after request finished.
+     * @property {HeadersCallback} onHeaders - Callback that provides additional request headers.
+     * @property {Object.<String, String>} headers - Additional request headers.
+     * @property {String} credentials - Credentials mode of requests: same-origin (default), include or omit.
+     * @property {Object} urlParams - Values for {name} placeholders in valueUrl and submitUrl, unknown placeholders are kept.
      *
      * @property {Object} value - Value, can be absent if provided with valueUrl.
      * @property {Object} schema - Schema, can be absent if provided with schemaUrl.
@@ -91,6 +154,8 @@
          */
         var params = this.queryParams()
 
+        params.urlParams = pickUrlParams(params, [params.submitUrl, params.valueUrl])
+
         this.make(params)
     }
 
@@ -121,23 +186,35 @@
         // console.log("QUERY PARAMS:", params)
 
         if (params.onSuccess) {
-            this.success = params.onSuccess
+            this.success = resolveCallback(params.onSuccess)
         }
 
         if (params.onFail) {
-            this.fail = params.onFail
+            this.fail = resolveCallback(params.onFail)
         }
 
         if (params.onError) {
-            this.error = params.onError
+            this.error = resolveCallback(params.onError)
         }
 
         if (params.onBeforeSubmit) {
-            this.beforeSubmit = params.onBeforeSubmit
+            this.beforeSubmit = resolveCallback(params.onBeforeSubmit)
         }
 
         if (params.onRequestFinished) {
-            this.requestFinished = params.onRequestFinished
+            this.requestFinished = resolveCallback(params.onRequestFinished)
+        }
+
+        if (params.onHeaders) {
+            this.headersProvider = resolveCallback(params.onHeaders)
+        }
+
+        if (isObject(params.headers)) {
+            this.headers = params.headers
+        }
+
+        if (params.credentials) {
+            this.credentials = params.credentials
         }
 
         var self = this
@@ -178,6 +255,14 @@
             return;
         }
 
+        if (params.urlParams) {
+            params.submitUrl = expandUrl(params.submitUrl, params.urlParams, true)
+
+            if (params.valueUrl) {
+                params.valueUrl = expandUrl(params.valueUrl, params.urlParams, true)
+            }
+        }
+
         if (params.submitMethod != null) {
             this.submitMethod = params.submitMethod;
         }
@@ -186,6 +271,26 @@
             this.successStatus = Number(params.successStatus);
         }
 
+        if (params.submitMode) {
+            this.submitMode = params.submitMode;
+        }
+
+        if (params.submitEncoding) {
+            this.submitEncoding = params.submitEncoding;
+        }
+
+        if (params.autosave === true || params.autosave === "true" || params.autosave === "1") {
+            this.autosave = true;
+        }
+
+        if (params.name) {
+            this.name = params.name;
+        }
+
+        if (params.successRedirect) {
+            this.successRedirect = params.successRedirect;
+        }
+
         this.submitUrl = params.submitUrl;
         this.schemaName = params.schemaName;
 
@@ -217,7 +322,7 @@
         if (this.schema === undefined) {
             var schemaUrl = this.schemaName + "-schema.json"
 
-            send(schemaUrl, "GET", null, 200, function (resp) {
+            send(self, schemaUrl, "GET", null, 200, function (resp) {
                 console.log("SCHEMA RESP", resp)
 
                 self.schema = JSON.parse(resp.responseText);
@@ -230,19 +335,20 @@
                 self.render()
             }, function (x) {
                 self.error("Failed to load schema using URL:<br /><code>" + schemaUrl + "</code><br />Response:<br /><code>" + x.responseText + "</code>", self)
-            }, null)
+            }, null, this.requestOptions(schemaUrl, "GET"))
 
             return
         }
 
         if (this.value === undefined && this.valueUrl !== undefined && this.valueUrl !== '') {
-            send(this.valueUrl, "GET", null, 200, function (resp) {
+            send(self, this.valueUrl, "GET", null, 200, function (resp) {
                 self.value = JSON.parse(resp.responseText);
+                self.etag = resp.getResponseHeader("ETag");
 
                 self.render()
             }, function (x) {
                 self.error("Failed to load value using URL:<br /><code>" + self.valueUrl + "</code><br />Response:<br /><code>" + x.responseText + "</code>", self)
-            }, null)
+            }, null, this.requestOptions(this.valueUrl, "GET"))
 
             return
         }
@@ -250,6 +356,21 @@
 
         // console.log("Rendering form")
 
+        this.renderForm(this.value)
+        this.markClean()
+
+        if (this.autosave) {
+            this.offerDraft()
+        }
+    }
+
+    /**
+     * Render form elements with value.
+     * @param {Object} value
+     */
+    JSONForm.prototype.renderForm = function (value) {
+        var self = this
+
         var formConf = {
             schema: this.schema.schema,
             form: this.schema.form,
@@ -269,16 +390,416 @@
                 }
 
                 if (self.submitUrl && self.submitMethod) {
-                    send(self, self.submitUrl, self.submitMethod, values, self.successStatus, self.success, self.fail, self.requestFinished)
+                    self.submit(values, self.etag)
                 }
             }
         }
 
-        if (typeof this.value !== undefined) {
-            formConf.value = this.value
+        if (value !== undefined) {
+            formConf.value = value
+        }
+
+        $(this.form).html('').jsonForm(formConf);
+
+        $(this.form).off('.jsonformDirty').on('input.jsonformDirty change.jsonformDirty click.jsonformDirty', function () {
+            self.markChanges();
+        });
+
+        if (this.autosave) {
+            var timer = null;
+
+            $(this.form).off('.jsonformDraft').on('input.jsonformDraft change.jsonformDraft', function () {
+                clearTimeout(timer);
+                timer = setTimeout(function () {
+                    self.saveDraft();
+                }, 500);
+            });
+        }
+    }
+
+    /**
+     * Remember current form values and field states as unchanged.
+     */
+    JSONForm.prototype.markClean = function () {
+        var self = this, form = $(this.form);
+
+        this.baseline = form.jsonFormValue();
+        this.initialFields = {};
+
+        form.find(':input[name]').each(function () {
+            self.initialFields[fieldKey(this)] = fieldState(this);
+        });
+
+        form.find('.jsonform-changed').removeClass('jsonform-changed warning');
+    }
+
+    /**
+     * Mark fields that differ from initial state with "jsonform-changed" class.
+     */
+    JSONForm.prototype.markChanges = function () {
+        var self = this, form = $(this.form);
+
+        form.find('.jsonform-changed').removeClass('jsonform-changed warning');
+
+        form.find(':input[name]').each(function () {
+            if (self.initialFields[fieldKey(this)] !== fieldState(this)) {
+                var group = $(this).closest('.control-group');
+
+                (group.length ? group : $(this)).addClass('jsonform-changed warning');
+            }
+        });
+    }
+
+    /**
+     * Check if form has unsaved changes.
+     * @return {Boolean}
+     */
+    JSONForm.prototype.isDirty = function () {
+        if (this.baseline === undefined) {
+            return false;
+        }
+
+        return !jsonEqual($(this.form).jsonFormValue(), this.baseline);
+    }
+
+    /**
+     * Discard unsaved changes and draft.
+     */
+    JSONForm.prototype.reset = function () {
+        if (this.baseline === undefined) {
+            return;
+        }
+
+        this.renderForm(this.value);
+        this.markClean();
+
+        if (this.autosave) {
+            this.clearDraft();
+        }
+    }
+
+    /**
+     * Storage key of draft, drafts are kept per schema and value URL.
+     * @return {String}
+     */
+    JSONForm.prototype.draftKey = function () {
+        return "jsonform-draft:" + (this.schemaName || window.location.pathname + "#" + this.name) + ":" + (this.valueUrl || "");
+    }
+
+    /**
+     * Save current form values as draft.
+     */
+    JSONForm.prototype.saveDraft = function () {
+        try {
+            window.localStorage.setItem(this.draftKey(), JSON.stringify({
+                hash: hashString(JSON.stringify(this.schema)),
+                savedAt: new Date().toISOString(),
+                value: $(this.form).jsonFormValue()
+            }));
+        } catch (e) {
+            console.log("failed to save draft", e);
+        }
+    }
+
+    /**
+     * Load draft, drafts of a different schema are discarded.
+     * @return {{savedAt: String, value: Object}|null}
+     */
+    JSONForm.prototype.loadDraft = function () {
+        try {
+            var draft = JSON.parse(window.localStorage.getItem(this.draftKey()));
+
+            if (draft && draft.hash === hashString(JSON.stringify(this.schema))) {
+                return draft;
+            }
+
+            this.clearDraft();
+        } catch (e) {
+            console.log("failed to load draft", e);
+        }
+
+        return null;
+    }
+
+    /**
+     * Remove draft from storage.
+     */
+    JSONForm.prototype.clearDraft = function () {
+        try {
+            window.localStorage.removeItem(this.draftKey());
+        } catch (e) {
+            console.log("failed to remove draft", e);
+        }
+    }
+
+    /**
+     * Offer to restore saved draft if it differs from form value.
+     */
+    JSONForm.prototype.offerDraft = function () {
+        var self = this, draft = this.loadDraft();
+
+        // Draft is a form value, so it is compared with form value of initial value, not with the raw value.
+        if (draft === null || jsonEqual(draft.value, this.baseline)) {
+            return;
+        }
+
+        var restore = $('<button type="button" class="btn btn-primary">Restore</button>').on('click', function () {
+            self.result.html('').hide();
+            self.renderForm(draft.value);
+            self.markChanges();
+        });
+
+        var discard = $('<button type="button" class="btn">Discard</button>').on('click', function () {
+            self.result.html('').hide();
+            self.clearDraft();
+        });
+
+        self.result.html('').append($('<p>').text("Unsaved draft from " + new Date(draft.savedAt).toLocaleString() + " is found."))
+            .append(restore, ' ', discard).show();
+    }
+
+    /**
+     * Prepare request options with headers and credentials mode of form.
+     * @param {String} url
+     * @param {String} method
+     * @param {Object} [options] - Request options, see send.
+     * @return {Object}
+     */
+    JSONForm.prototype.requestOptions = function (url, method, options) {
+        return requestOptions(this, url, method, options);
+    }
+
+    /**
+     * Submit form values.
+     * @param {Object} values - Form values.
+     * @param {String|null} etag - ETag of initial value to send in If-Match header.
+     */
+    JSONForm.prototype.submit = function (values, etag) {
+        var self = this, body = values, contentType = null, encoding = this.submitEncoding, headers = {};
+
+        // Changes are made against form values of initial value, so that fields without form items
+        // (e.g. id or fields removed by policies) are not patched.
+        var before = this.baseline || {};
+
+        switch (this.submitMode) {
+            case 'merge-patch':
+                body = mergePatch(before, values);
+                contentType = "application/merge-patch+json";
+                encoding = 'json';
+                break;
+            case 'json-patch':
+                body = jsonPatch(before, values, '', []);
+                contentType = "application/json-patch+json";
+                encoding = 'json';
+                break;
+        }
+
+        if (etag) {
+            headers["If-Match"] = etag;
+        }
+
+        send(self, this.submitUrl, this.submitMethod, body, this.successStatus, function (x, ctx) {
+            // Submitted values are the new base for changes.
+            self.value = values;
+
+            if (x.getResponseHeader("ETag")) {
+                self.etag = x.getResponseHeader("ETag");
+            } else if (self.etag && self.valueUrl) {
+                self.refreshETag();
+            }
+
+            if (self.autosave) {
+                self.clearDraft();
+            }
+
+            self.markClean();
+
+            // Success callback is called before navigation, e.g. to show a notification or to track an event.
+            if (typeof (self.success) === 'function') {
+                self.success(x, ctx);
+            }
+
+            if (self.successRedirect) {
+                self.redirect(x);
+            }
+        }, function (x, ctx) {
+            if (x.status === 412 && etag) {
+                self.conflict(values);
+
+                return;
+            }
+
+            if (self.showErrors(x)) {
+                return;
+            }
+
+            if (typeof (self.fail) === 'function') {
+                self.fail(x, ctx);
+            }
+        }, this.requestFinished, this.requestOptions(this.submitUrl, this.submitMethod, {
+            contentType: contentType,
+            encoding: encoding,
+            headers: headers
+        }))
+    }
+
+    /**
+     * Load ETag of updated value from valueUrl, if submit response does not have it.
+     */
+    JSONForm.prototype.refreshETag = function () {
+        var self = this;
+
+        send(self, this.valueUrl, "GET", null, 200, function (resp) {
+            self.etag = resp.getResponseHeader("ETag");
+        }, function (x) {
+            console.log("failed to refresh ETag", x.status);
+        }, null, this.requestOptions(this.valueUrl, "GET"));
+    }
+
+    /**
+     * Navigate to successRedirect URL filled with fields of response JSON.
+     * @param {XMLHttpRequest} x - Successful response.
+     */
+    JSONForm.prototype.redirect = function (x) {
+        var resp = {}, url;
+
+        try {
+            if (x.responseText) {
+                resp = JSON.parse(x.responseText);
+            }
+
+            url = new URL(expandUrl(this.successRedirect, isObject(resp) ? resp : {}), window.location.href);
+        } catch (e) {
+            this.error("Failed to redirect after submit: " + e.message, this);
+
+            return;
+        }
+
+        // Only same-origin navigation is allowed, URL template can come from query parameters.
+        if (url.origin !== window.location.origin) {
+            this.error("Failed to redirect after submit: cross-origin URL", this);
+
+            return;
+        }
+
+        window.location.assign(url.href);
+    }
+
+    /**
+     * Show field errors of swaggest/rest error response next to fields.
+     *
+     * Error context contains messages prefixed with JSON pointer of the value, e.g.
+     * {"status":"INVALID_ARGUMENT","error":"invalid argument: validation failed",
+     *  "context":{"body":["#/user/firstName: length must be >= 3, but got 2"]}}
+     *
+     * @param {XMLHttpRequest} x - Failed response.
+     * @return {Boolean} - True if errors were shown.
+     */
+    JSONForm.prototype.showErrors = function (x) {
+        var resp;
+
+        try {
+            resp = JSON.parse(x.responseText);
+        } catch (e) {
+            return false;
+        }
+
+        if (!isObject(resp) || !isObject(resp.context)) {
+            return false;
+        }
+
+        var fieldErrors = [], other = [];
+
+        Object.keys(resp.context).forEach(function (name) {
+            var messages = resp.context[name];
+
+            if (!Array.isArray(messages)) {
+                return;
+            }
+
+            messages.forEach(function (msg) {
+                var m = /^(#[^:]*): (.*)$/.exec(msg);
+
+                if (name !== "body" || m === null) {
+                    other.push(name + ": " + msg);
+
+                    return;
+                }
+
+                var pointer = m[1], message = m[2];
+
+                // Missing properties are reported for parent object.
+                var missing = /^missing propert(y|ies): (.+)$/.exec(message);
+                if (missing !== null) {
+                    missing[2].split(/,\s*/).forEach(function (prop) {
+                        fieldErrors.push({uri: pointer + "/" + prop.replace(/^['"]|['"]$/g, ''), message: "Required."});
+                    });
+
+                    return;
+                }
+
+                if (pointer === "#" || pointer === "#/") {
+                    other.push(message);
+
+                    return;
+                }
+
+                fieldErrors.push({uri: pointer, message: $('<span>').text(message).html()});
+            });
+        });
+
+        if (fieldErrors.length === 0) {
+            return false;
+        }
+
+        // Pointers are mapped to form item keys, e.g. "#/neighbors/1/firstName" to "neighbors[1].firstName".
+        $(this.form).jsonFormErrors(fieldErrors);
+        this.markChanges();
+
+        var summary = $('<div>').append($('<p>').text(resp.error || "Validation failed."));
+
+        if (other.length > 0) {
+            var list = $('<ul>');
+
+            other.forEach(function (msg) {
+                list.append($('<li>').text(msg));
+            });
+
+            summary.append(list);
+        }
+
+        this.result.html('').append(summary).show();
+
+        return true;
+    }
+
+    /**
+     * Show conflict view for a value that was changed after it was loaded.
+     * @param {Object} values - Form values that failed to submit.
+     */
+    JSONForm.prototype.conflict = function (values) {
+        var self = this;
+
+        var overwrite = $('<button type="button" class="btn btn-danger">Overwrite</button>').on('click', function () {
+            self.result.html('').hide();
+            self.submit(values, null);
+        });
+
+        self.result.html("<p>The value was changed by someone else after it was loaded. " +
+            "Reload it to discard your changes, or overwrite the changes of others.</p>");
+
+        if (this.valueUrl) {
+            var reload = $('<button type="button" class="btn">Reload</button>').on('click', function () {
+                self.result.html('').hide();
+                self.value = undefined;
+                self.etag = null;
+                self.render();
+            });
+
+            self.result.append(reload, ' ');
         }
 
-        $(this.form).jsonForm(formConf);
+        self.result.append(overwrite).show();
     }
 
     /**
@@ -319,7 +840,7 @@
 
         return query.split('&').reduce(function (res, item) {
             var parts = item.split('=');
-            res[parts[0]] = decodeURIComponent(parts[1]);
+            res[decodeURIComponent(parts[0])] = decodeURIComponent(parts.slice(1).join('=').replace(/\+/g, ' '));
             return res;
         }, {})
     }
@@ -347,41 +868,228 @@
 
 
     /**
-     * @callback RawCallback
-     * @param {XMLHttpRequest} value
-     * @param ctx - parent context.
+     * Created forms.
+     * @type {JSONForm[]}
      */
+    var instances = [];
+
+    // Warn about unsaved changes when leaving the page.
+    window.addEventListener('beforeunload', function (e) {
+        for (var i = 0; i < instances.length; i++) {
+            if (instances[i].isDirty()) {
+                e.preventDefault();
+                e.returnValue = '';
+
+                return '';
+            }
+        }
+    });
 
     /**
-     * @callback HTMLCallback
-     * @param {String} value
-     * @param ctx - parent context.
+     * @param {Element} el - Form field.
+     * @return {String} - Identifier of field, checkboxes and radios are identified with value.
      */
+    function fieldKey(el) {
+        if (el.type === 'checkbox' || el.type === 'radio') {
+            return el.name + '=' + el.value;
+        }
+
+        return el.name;
+    }
 
     /**
-     * @callback JSONCallback
-     * @param {Object} value
-     * @param ctx - parent context.
+     * @param {Element} el - Form field.
+     * @return {String}
      */
+    function fieldState(el) {
+        if (el.type === 'checkbox' || el.type === 'radio') {
+            return String(el.checked);
+        }
+
+        return String($(el).val());
+    }
 
     /**
-     *
-     * @param ctx - passed as last argument to callbacks.
-     * @param {String} url
-     * @param {String} method
-     * @param {Object} bodyValues
-     * @param {Number} successStatus
-     * @param {RawCallback} successCallback
-     * @param {RawCallback} failCallback
-     * @param {RawCallback} finishCallback
+     * Registered callbacks by name.
+     * @type {Object.<String, Function>}
      */
-    function send(ctx, url, method, bodyValues, successStatus, successCallback, failCallback, finishCallback) {
-        var x = new XMLHttpRequest();
-        x.onreadystatechange = function () {
-            if (x.readyState !== XMLHttpRequest.DONE) {
-                return;
+    var callbacks = {};
+
+    /**
+     * Headers provider for forms without onHeaders callback.
+     * @type {HeadersCallback|null}
+     */
+    var defaultHeadersProvider = null;
+
+    /**
+     * Set headers provider for all forms without onHeaders callback, e.g. for dynamic forms of form.html.
+     * @param {HeadersCallback} fn
+     */
+    JSONForm.setHeadersProvider = function (fn) {
+        defaultHeadersProvider = fn;
+    }
+
+    /**
+     * Origins that receive form headers and credentials in addition to origin of the page.
+     * @type {Array.<String>}
+     */
+    var trustedOrigins = [];
+
+    /**
+     * Set origins of cross-origin APIs that receive headers and credentials of forms and lists,
+     * e.g. ["https://api.example.com"].
+     * @param {Array.<String>} origins
+     */
+    JSONForm.setTrustedOrigins = function (origins) {
+        trustedOrigins = origins || [];
+    }
+
+    /**
+     * Check if URL is of page origin or of a trusted origin.
+     * URLs may come from query parameters of form.html, so headers and tokens must not be sent to other origins.
+     * @param {String} url
+     * @return {Boolean}
+     */
+    function isTrustedUrl(url) {
+        var origin;
+
+        try {
+            origin = new URL(url, location.href).origin;
+        } catch (e) {
+            return false;
+        }
+
+        return origin === location.origin || trustedOrigins.indexOf(origin) !== -1;
+    }
+
+    /**
+     * Register a named callback to reference it from form parameters.
+     * @param {String} name
+     * @param {Function} fn
+     */
+    JSONForm.registerCallback = function (name, fn) {
+        callbacks[name] = fn;
+    }
+
+    /**
+     * Resolve callback that can be a function, or a name of registered or global function.
+     * @param {Function|String} cb
+     * @return {Function}
+     */
+    function resolveCallback(cb) {
+        if (typeof cb === 'function') {
+            return cb;
+        }
+
+        if (callbacks.hasOwnProperty(cb)) {
+            return callbacks[cb];
+        }
+
+        var fn = cb.split('.').reduce(function (obj, name) {
+            return obj == null ? obj : obj[name];
+        }, window);
+
+        if (typeof fn !== 'function') {
+            throw new Error("Unknown callback: " + cb);
+        }
+
+        return fn;
+    }
+
+    /**
+     * Initialize forms of elements with data-jsonform (JSON params) or data-jsonform-query (params from URL) attributes.
+     * @param {Element} root - Optional root element to search forms in, document by default.
+     */
+    JSONForm.init = function (root) {
+        $('[data-jsonform]', root || document).each(function () {
+            var container = $(this);
+            var form = new JSONForm();
+
+            form.setFormElement($('form', container));
+            form.setTitleElement($('h1', container));
+            form.setDescriptionElement($('.form-description', container));
+            form.setResultElement($('.alert', container));
+            form.make(JSON.parse(container.attr('data-jsonform')));
+        });
+
+        $('[data-jsonform-query]', root || document).each(function () {
+            var container = $(this);
+            var form = new JSONForm();
+
+            form.setFormElement($('form', container));
+            form.setTitleElement($('h1', container));
+            form.setResultElement($('.alert', container));
+            form.default();
+        });
+    }
+
+    /**
+     * @callback RawCallback
+     * @param {XMLHttpRequest} value
+     * @param ctx - parent context.
+     */
+
+    /**
+     * @callback HTMLCallback
+     * @param {String} value
+     * @param ctx - parent context.
+     */
+
+    /**
+     * @callback HeadersCallback
+     * @param {String} url
+     * @param {String} method
+     * @param ctx - parent context.
+     * @return {Object.<String, String>} - Request headers.
+     */
+
+    /**
+     * @callback JSONCallback
+     * @param {Object} value
+     * @param ctx - parent context.
+     */
+
+    /**
+     *
+     * @param ctx - passed as last argument to callbacks.
+     * @param {String} url
+     * @param {String} method
+     * @param {Object} bodyValues
+     * @param {Number} successStatus
+     * @param {RawCallback} successCallback
+     * @param {RawCallback} failCallback
+     * @param {RawCallback} finishCallback
+     * @param {Object} [options]
+     * @param {String} [options.contentType] - Content type of JSON request body, default application/json.
+     * @param {String} [options.encoding] - Encoding of request body: json (default), form-urlencoded or multipart.
+     * @param {Object} [options.headers] - Additional request headers.
+     * @param {String} [options.credentials] - Credentials mode: same-origin (default), include or omit.
+     */
+    function send(ctx, url, method, bodyValues, successStatus, successCallback, failCallback, finishCallback, options) {
+        options = options || {};
+
+        var headers = $.extend({}, options.headers || {}), body = null;
+
+        // Anti-forgery token is not sent to other origins, see isTrustedUrl.
+        if (method !== "GET" && method !== "HEAD" && isTrustedUrl(url)) {
+            var csrf = csrfToken();
+            if (csrf !== null) {
+                headers[csrf.header] = csrf.token;
             }
+        }
+
+        if (bodyValues !== null && options.encoding === 'form-urlencoded') {
+            headers["Content-Type"] = "application/x-www-form-urlencoded; charset=utf-8";
+            body = encodeFields(bodyValues, '', new URLSearchParams()).toString();
+        } else if (bodyValues !== null && options.encoding === 'multipart') {
+            // Content-Type with boundary is set by browser.
+            body = encodeFields(bodyValues, '', new FormData());
+        } else if (bodyValues !== null) {
+            headers["Content-Type"] = (options.contentType || "application/json") + "; charset=utf-8";
+            body = JSON.stringify(bodyValues);
+        }
 
+        var done = function (x) {
             console.log("request finished with status", x.status, "expected status", successStatus)
 
             if (typeof (finishCallback) === 'function') {
@@ -412,18 +1120,579 @@
             }
         };
 
+        // XMLHttpRequest always sends same-origin cookies, fetch is used to omit them.
+        if (options.credentials === 'omit') {
+            fetchResponse(url, {method: method, headers: headers, body: body, credentials: 'omit'}, done);
+
+            return;
+        }
+
+        var x = new XMLHttpRequest();
+        x.onreadystatechange = function () {
+            if (x.readyState !== XMLHttpRequest.DONE) {
+                return;
+            }
+
+            done(x);
+        };
+
 
         x.open(method, url, true);
-        if (typeof bodyValues !== null) {
-            x.setRequestHeader("Content-Type", "application/json; charset=utf-8");
-            x.send(JSON.stringify(bodyValues));
+
+        if (options.credentials === 'include') {
+            x.withCredentials = true;
+        }
+
+        Object.keys(headers).forEach(function (name) {
+            x.setRequestHeader(name, headers[name]);
+        });
+
+        x.send(body);
+    }
+
+    /**
+     * Make request with fetch and pass response as XMLHttpRequest-like object with status,
+     * responseText and getResponseHeader, network error results in status 0.
+     * @param {String} url
+     * @param {Object} init - Fetch options.
+     * @param {Function} done - Receives response.
+     */
+    function fetchResponse(url, init, done) {
+        var failed = {
+            status: 0, responseText: '', getResponseHeader: function () {
+                return null;
+            }
+        };
+
+        fetch(url, init).then(function (resp) {
+            return resp.text().then(function (text) {
+                return {
+                    status: resp.status, responseText: text, getResponseHeader: function (name) {
+                        return resp.headers.get(name);
+                    }
+                };
+            });
+        }).then(done, function (e) {
+            console.log("request failed", e);
+            done(failed);
+        });
+    }
+
+    /**
+     * Prepare request options with headers and credentials mode of a form or a list.
+     * Headers and credentials mode are only applied to URLs of page origin or trusted origins, see setTrustedOrigins.
+     * @param {JSONForm|JSONList} ctx - Has headers, headersProvider and credentials.
+     * @param {String} url
+     * @param {String} method
+     * @param {Object} [options] - Request options, see send.
+     * @return {Object}
+     */
+    function requestOptions(ctx, url, method, options) {
+        options = options || {};
+
+        if (!isTrustedUrl(url)) {
+            options.headers = $.extend({}, options.headers || {});
+            options.credentials = ctx.credentials === 'omit' ? 'omit' : '';
+
+            return options;
+        }
+
+        var headers = $.extend({}, ctx.headers), provider = ctx.headersProvider || defaultHeadersProvider;
+
+        if (typeof provider === 'function') {
+            $.extend(headers, provider(url, method, ctx) || {});
+        }
+
+        options.headers = $.extend(headers, options.headers || {});
+        options.credentials = ctx.credentials;
+
+        return options;
+    }
+
+    /**
+     * Add values as fields named by form item keys, e.g. "user.firstName" or "neighbors[0].age".
+     * @param {*} value
+     * @param {String} name - Field name of value.
+     * @param {URLSearchParams|FormData} fields - Fields to append to.
+     * @return {URLSearchParams|FormData}
+     */
+    function encodeFields(value, name, fields) {
+        if (Array.isArray(value)) {
+            value.forEach(function (item, i) {
+                encodeFields(item, name + "[" + i + "]", fields);
+            });
+        } else if (isObject(value)) {
+            Object.keys(value).forEach(function (k) {
+                encodeFields(value[k], name ? name + "." + k : k, fields);
+            });
+        } else if (value !== null && value !== undefined) {
+            fields.append(name, String(value));
+        }
+
+        return fields;
+    }
+
+    /**
+     * FNV-1a hash of a string.
+     * @param {String} s
+     * @return {String}
+     */
+    function hashString(s) {
+        var h = 0x811c9dc5;
+
+        for (var i = 0; i < s.length; i++) {
+            h ^= s.charCodeAt(i);
+            h = Math.imul(h, 0x01000193) >>> 0;
+        }
+
+        return h.toString(16);
+    }
+
+    /**
+     * @param {*} v
+     * @return {Boolean}
+     */
+    function isObject(v) {
+        return v !== null && typeof v === 'object' && !Array.isArray(v);
+    }
+
+    /**
+     * Compare JSON values.
+     * @param {*} a
+     * @param {*} b
+     * @return {Boolean}
+     */
+    function jsonEqual(a, b) {
+        if (Array.isArray(a) && Array.isArray(b)) {
+            if (a.length !== b.length) {
+                return false;
+            }
+
+            for (var i = 0; i < a.length; i++) {
+                if (!jsonEqual(a[i], b[i])) {
+                    return false;
+                }
+            }
+
+            return true;
+        }
+
+        if (isObject(a) && isObject(b)) {
+            var keys = Object.keys(a);
+
+            if (keys.length !== Object.keys(b).length) {
+                return false;
+            }
+
+            for (var k = 0; k < keys.length; k++) {
+                if (!b.hasOwnProperty(keys[k]) || !jsonEqual(a[keys[k]], b[keys[k]])) {
+                    return false;
+                }
+            }
+
+            return true;
+        }
+
+        return a === b;
+    }
+
+    /**
+     * Create JSON Merge Patch (RFC 7396) of changes.
+     * @param {*} before
+     * @param {*} after
+     * @return {*}
+     */
+    function mergePatch(before, after) {
+        if (!isObject(before) || !isObject(after)) {
+            return after;
+        }
+
+        var patch = {};
+
+        Object.keys(before).forEach(function (k) {
+            if (!after.hasOwnProperty(k)) {
+                patch[k] = null;
+            }
+        });
+
+        Object.keys(after).forEach(function (k) {
+            if (!before.hasOwnProperty(k)) {
+                patch[k] = after[k];
+            } else if (!jsonEqual(before[k], after[k])) {
+                patch[k] = mergePatch(before[k], after[k]);
+            }
+        });
+
+        return patch;
+    }
+
+    /**
+     * Create JSON Patch (RFC 6902) operations of changes, arrays are replaced as a whole.
+     * @param {*} before
+     * @param {*} after
+     * @param {String} path - JSON Pointer of values.
+     * @param {Array} ops - Operations to append to.
+     * @return {Array}
+     */
+    function jsonPatch(before, after, path, ops) {
+        if (!isObject(before) || !isObject(after)) {
+            if (!jsonEqual(before, after)) {
+                ops.push({op: "replace", path: path, value: after});
+            }
+
+            return ops;
+        }
+
+        var pointer = function (k) {
+            return path + "/" + k.replace(/~/g, "~0").replace(/\//g, "~1");
+        };
+
+        Object.keys(before).forEach(function (k) {
+            if (!after.hasOwnProperty(k)) {
+                ops.push({op: "remove", path: pointer(k)});
+            }
+        });
+
+        Object.keys(after).forEach(function (k) {
+            if (!before.hasOwnProperty(k)) {
+                ops.push({op: "add", path: pointer(k), value: after[k]});
+            } else {
+                jsonPatch(before[k], after[k], pointer(k), ops);
+            }
+        });
+
+        return ops;
+    }
+
+    var placeholderPattern = /{([^}:]+)(:[^}]*)?}/g;
+
+    /**
+     * Replace {name} placeholders in URL with escaped values.
+     * Values are escaped as path segments before "?" and as query values after it.
+     * @param {String} url
+     * @param {Object} values
+     * @param {Boolean} [keepMissing] - Leave placeholders without values as is instead of throwing.
+     * @return {String}
+     * @throws {Error} if value of placeholder is missing.
+     */
+    function expandUrl(url, values, keepMissing) {
+        var query = url.search(/[?#]/);
+
+        return url.replace(placeholderPattern, function (placeholder, name, pattern, offset) {
+            var value = lookupValue(values, name);
+
+            if (value === undefined) {
+                if (keepMissing) {
+                    return placeholder;
+                }
+
+                throw new Error("Missing URL parameter <code>" + $('<span>').text(name).html() + "</code> in <code>" +
+                    $('<span>').text(url).html() + "</code>");
+            }
+
+            if (query !== -1 && offset > query) {
+                return encodeURIComponent(value).replace(/%20/g, '+');
+            }
+
+            return encodeURIComponent(value);
+        });
+    }
+
+    /**
+     * Pick values of {name} placeholders used in URL templates.
+     * @param {Object} values
+     * @param {Array.<String>} urls - URL templates, empty values are skipped.
+     * @return {Object|null} - Values of referenced names or null if there are no placeholders.
+     */
+    function pickUrlParams(values, urls) {
+        var res = null;
+
+        for (var i = 0; i < urls.length; i++) {
+            if (!urls[i]) {
+                continue;
+            }
+
+            urls[i].replace(placeholderPattern, function (placeholder, name) {
+                res = res || {};
+
+                if (values.hasOwnProperty(name)) {
+                    res[name] = values[name];
+                }
+
+                return placeholder;
+            });
+        }
+
+        return res;
+    }
+
+    /**
+     * Find value by name or by dotted path of nested objects, e.g. "user.id".
+     * @param {Object} values
+     * @param {String} name
+     * @return {*} - Value or undefined if it is missing.
+     */
+    function lookupValue(values, name) {
+        if (values.hasOwnProperty(name)) {
+            return values[name];
+        }
+
+        var v = values, parts = name.split('.');
+
+        for (var i = 0; i < parts.length; i++) {
+            if (!isObject(v) || !v.hasOwnProperty(parts[i])) {
+                return undefined;
+            }
+
+            v = v[parts[i]];
+        }
+
+        return v;
+    }
+
+    /**
+     * Get anti-forgery token from page meta tags or from cookie.
+     * @return {{header: String, token: String}|null}
+     */
+    function csrfToken() {
+        var header = $('meta[name="csrf-header"]').attr('content') || "X-CSRF-Token";
+        var token = $('meta[name="csrf-token"]').attr('content');
+        var cookie = $('meta[name="csrf-cookie"]').attr('content') || "jsonform_csrf";
+
+        if (!token) {
+            var cookies = document.cookie.split(';');
+            for (var i = 0; i < cookies.length; i++) {
+                var parts = cookies[i].trim().split('=');
+                if (parts[0] === cookie) {
+                    token = decodeURIComponent(parts.slice(1).join('='));
+                }
+            }
+        }
+
+        if (!token) {
+            return null;
+        }
+
+        return {header: header, token: token};
+    }
+
+    /**
+     * List of values rendered as table.
+     * @param {Element} container - Element with table and result element.
+     * @param {Object} params
+     * @param {{key: String, title: String, sortable: Boolean, titleMap: Object}[]} params.columns
+     * @param {Object[]} [params.items] - Embedded items.
+     * @param {String} [params.listUrl] - URL to load items from.
+     * @param {String} [params.valueKey] - Name of item field with value, if items are envelopes.
+     * @param {String} [params.editUrl] - URL template of edit link.
+     * @param {String} [params.deleteUrl] - URL template of DELETE request.
+     * @param {Object.<String, String>} [params.headers] - Additional request headers.
+     * @param {String} [params.onHeaders] - Name of callback that provides additional request headers.
+     * @param {String} [params.credentials] - Credentials mode of requests: same-origin (default), include or omit.
+     * @constructor
+     */
+    function JSONList(container, params) {
+        this.container = container;
+        this.result = $('.alert', container);
+        this.columns = params.columns || [];
+        this.items = params.items || null;
+        this.listUrl = params.listUrl || '';
+        this.valueKey = params.valueKey || '';
+        this.editUrl = params.editUrl || '';
+        this.deleteUrl = params.deleteUrl || '';
+        this.headers = isObject(params.headers) ? params.headers : {};
+        this.headersProvider = params.onHeaders ? resolveCallback(params.onHeaders) : null;
+        this.credentials = params.credentials || '';
+
+        /**
+         * @type {{key: String, desc: Boolean}|null}
+         */
+        this.sort = null;
+    }
+
+    /**
+     * Load items if needed and render table.
+     */
+    JSONList.prototype.make = function () {
+        var self = this;
+
+        $('th[data-key]', this.container).each(function () {
+            var th = $(this), key = th.attr('data-key');
+
+            if (!self.columns.some(function (c) {
+                return c.key === key && c.sortable;
+            })) {
+                return;
+            }
+
+            th.css('cursor', 'pointer').on('click', function () {
+                self.sort = {key: key, desc: self.sort !== null && self.sort.key === key && !self.sort.desc};
+
+                $('th[data-key] .jsonform-sort', self.container).remove();
+                th.append($('<span class="jsonform-sort">').text(self.sort.desc ? ' ▼' : ' ▲'));
+
+                self.render();
+            });
+        });
+
+        if (this.items === null && this.listUrl !== '') {
+            send(this, this.listUrl, "GET", null, 200, function (resp) {
+                self.items = JSON.parse(resp.responseText) || [];
+                self.render();
+            }, function (x) {
+                self.error("Failed to load list using URL:<br /><code>" + $('<span>').text(self.listUrl).html() +
+                    "</code><br />Response:<br /><code>" + $('<span>').text(x.responseText).html() + "</code>");
+            }, null, this.requestOptions(this.listUrl, "GET"));
+
             return;
         }
 
-        x.send();
+        this.render();
+    }
+
+    /**
+     * @param {String} html
+     */
+    JSONList.prototype.error = function (html) {
+        this.result.addClass('alert-danger').html('ERROR: ' + html).show();
+    }
+
+    /**
+     * @param {String} url
+     * @param {String} method
+     * @return {Object} - Request options, see send.
+     */
+    JSONList.prototype.requestOptions = function (url, method) {
+        return requestOptions(this, url, method);
+    }
+
+    /**
+     * @param {Object} item
+     * @param {String} key - Column key.
+     * @return {*}
+     */
+    JSONList.prototype.cellValue = function (item, key) {
+        var value = this.valueKey !== '' ? item[this.valueKey] : item;
+
+        return isObject(value) ? lookupValue(value, key) : undefined;
+    }
+
+    /**
+     * Render table rows.
+     */
+    JSONList.prototype.render = function () {
+        var self = this, tbody = $('tbody', this.container), items = (this.items || []).slice();
+
+        if (this.sort !== null) {
+            var key = this.sort.key, dir = this.sort.desc ? -1 : 1;
+
+            items.sort(function (a, b) {
+                var av = self.cellValue(a, key), bv = self.cellValue(b, key);
+
+                if (av == null || bv == null) {
+                    return av == null ? (bv == null ? 0 : 1) : -1;
+                }
+
+                if (typeof av === 'number' && typeof bv === 'number') {
+                    return (av - bv) * dir;
+                }
+
+                return String(av).localeCompare(String(bv)) * dir;
+            });
+        }
+
+        tbody.empty();
+
+        items.forEach(function (item) {
+            var tr = $('<tr>');
+
+            self.columns.forEach(function (c) {
+                var v = self.cellValue(item, c.key);
+
+                if (v == null) {
+                    v = '';
+                } else if (c.titleMap && c.titleMap.hasOwnProperty(String(v))) {
+                    v = c.titleMap[String(v)];
+                } else if (typeof v === 'object') {
+                    v = JSON.stringify(v);
+                }
+
+                tr.append($('<td>').text(v));
+            });
+
+            if (self.editUrl !== '' || self.deleteUrl !== '') {
+                var actions = $('<td>');
+
+                try {
+                    if (self.editUrl !== '') {
+                        actions.append($('<a class="pure-button">').attr('href', expandUrl(self.editUrl, item)).text('Edit'), ' ');
+                    }
+
+                    if (self.deleteUrl !== '') {
+                        var deleteUrl = expandUrl(self.deleteUrl, item);
+
+                        actions.append($('<button type="button" class="pure-button">').text('Delete').on('click', function () {
+                            self.remove(item, deleteUrl);
+                        }));
+                    }
+                } catch (e) {
+                    self.error(e.message);
+                }
+
+                tr.append(actions);
+            }
+
+            tbody.append(tr);
+        });
+    }
+
+    /**
+     * Delete item with confirmation.
+     * @param {Object} item
+     * @param {String} url
+     */
+    JSONList.prototype.remove = function (item, url) {
+        var self = this;
+
+        if (!window.confirm("Delete this item?")) {
+            return;
+        }
+
+        send(this, url, "DELETE", null, 0, function (x) {
+            if (x.status < 200 || x.status >= 300) {
+                self.error("Failed to delete item using URL:<br /><code>" + $('<span>').text(url).html() +
+                    "</code><br />Status:<br /><code>" + x.status + "</code><br />Response:<br /><code>" +
+                    $('<span>').text(x.responseText).html() + "</code>");
+
+                return;
+            }
+
+            self.items = self.items.filter(function (i) {
+                return i !== item;
+            });
+            self.render();
+        }, null, null, this.requestOptions(url, "DELETE"));
+    }
+
+    /**
+     * Initialize lists of elements with data-jsonform-list (JSON params) attribute.
+     * @param {Element} root - Optional root element to search lists in, document by default.
+     */
+    JSONList.init = function (root) {
+        $('[data-jsonform-list]', root || document).each(function () {
+            var container = $(this);
+
+            new JSONList(container, JSON.parse(container.attr('data-jsonform-list'))).make();
+        });
     }
 
     window.JSONForm = JSONForm;
+    window.JSONList = JSONList;
+
+    $(function () {
+        JSONForm.init();
+        JSONList.init();
+    });
 })();
 
 function startSpinner(x, ctx) {
@@ -0,0 +1,347 @@
+// Tests of static/form.js with minimal browser stubs, run with node by TestFormJS.
+// Source of form.js is read from stdin: node testdata/form_test.js < static/form.js
+"use strict";
+
+const assert = require("assert");
+const fs = require("fs");
+const vm = require("vm");
+
+const source = fs.readFileSync(0, "utf8");
+
+/**
+ * Load form.js in a sandbox with stubs of window, document, jQuery and XMLHttpRequest.
+ * @return {Object} - Sandbox with JSONForm, JSONList, requests made and page state.
+ */
+function load() {
+    const sb = {
+        console: {log: function () {}},
+        URL: URL,
+        URLSearchParams: URLSearchParams,
+        FormData: FormData,
+        setTimeout: setTimeout,
+        clearTimeout: clearTimeout,
+        requests: [],
+        fetches: [],
+        meta: {},
+        formValue: {},
+        storage: {},
+        assigned: null,
+        document: {cookie: ""},
+    };
+
+    sb.window = sb;
+    sb.location = {search: "", href: "http://localhost/page", origin: "http://localhost", pathname: "/page"};
+    sb.location.assign = function (url) {
+        sb.assigned = url;
+    };
+    sb.addEventListener = function () {};
+    sb.localStorage = {
+        getItem: (k) => (sb.storage.hasOwnProperty(k) ? sb.storage[k] : null),
+        setItem: (k, v) => {
+            sb.storage[k] = String(v);
+        },
+        removeItem: (k) => {
+            delete sb.storage[k];
+        },
+    };
+
+    function Query(selector) {
+        this.selector = selector;
+        this.length = 0;
+    }
+
+    ["html", "hide", "show", "append", "removeClass", "addClass", "off", "on", "each", "jsonForm"].forEach((m) => {
+        Query.prototype[m] = function () {
+            return this;
+        };
+    });
+    Query.prototype.text = function (v) {
+        return v === undefined ? "" : this;
+    };
+    Query.prototype.find = Query.prototype.closest = function () {
+        return new Query();
+    };
+    Query.prototype.attr = function (name) {
+        const m = /^meta\[name="([^"]+)"]$/.exec(this.selector);
+
+        return m && name === "content" ? sb.meta[m[1]] : undefined;
+    };
+    Query.prototype.jsonFormValue = function () {
+        return JSON.parse(JSON.stringify(sb.formValue));
+    };
+
+    sb.$ = function (selector) {
+        return typeof selector === "function" ? undefined : new Query(selector);
+    };
+    sb.$.extend = Object.assign;
+
+    function XMLHttpRequest() {
+        this.headers = {};
+        this.responseHeaders = {};
+        this.readyState = 0;
+        sb.requests.push(this);
+    }
+
+    XMLHttpRequest.DONE = 4;
+    XMLHttpRequest.prototype.open = function (method, url) {
+        this.method = method;
+        this.url = url;
+    };
+    XMLHttpRequest.prototype.setRequestHeader = function (name, value) {
+        this.headers[name] = value;
+    };
+    XMLHttpRequest.prototype.send = function (body) {
+        this.body = body;
+    };
+    XMLHttpRequest.prototype.getResponseHeader = function (name) {
+        return this.responseHeaders.hasOwnProperty(name) ? this.responseHeaders[name] : null;
+    };
+    XMLHttpRequest.prototype.respond = function (status, body, headers) {
+        this.readyState = XMLHttpRequest.DONE;
+        this.status = status;
+        this.responseText = body || "";
+        this.responseHeaders = headers || {};
+        this.onreadystatechange();
+    };
+    sb.XMLHttpRequest = XMLHttpRequest;
+
+    sb.fetch = function (url, init) {
+        sb.fetches.push({url: url, init: init});
+
+        return Promise.resolve({
+            status: 200,
+            text: () => Promise.resolve('{"id":1}'),
+            headers: {get: (name) => (name === "ETag" ? '"v1"' : null)},
+        });
+    };
+
+    vm.createContext(sb);
+    vm.runInContext(source, sb, {filename: "form.js"});
+
+    return sb;
+}
+
+/**
+ * Create form with rendered value, only fields with form items are in form value.
+ * @param {Object} sb - Sandbox.
+ * @param {Object} value - Value of valueUrl.
+ * @param {Object} formValue - Value of rendered form.
+ * @return {JSONForm}
+ */
+function renderedForm(sb, value, formValue) {
+    const form = new sb.JSONForm();
+
+    form.setFormElement({});
+    form.setResultElement(sb.$());
+    form.schema = {schema: {}, form: []};
+    form.value = value;
+    sb.formValue = formValue;
+    form.render();
+
+    return form;
+}
+
+const tests = {
+    "patches only have fields of form items"() {
+        const sb = load();
+        const form = renderedForm(sb,
+            {id: 1, firstName: "John", lastName: "Doe", bio: "", createdAt: "2024-01-01"},
+            {firstName: "John", lastName: "Doe"});
+
+        form.submitUrl = "/user/1.json";
+        form.submitMethod = "PATCH";
+        form.submitMode = "merge-patch";
+        form.submit({firstName: "Jim", lastName: "Doe"}, null);
+
+        assert.strictEqual(sb.requests[0].headers["Content-Type"], "application/merge-patch+json; charset=utf-8");
+        assert.deepStrictEqual(JSON.parse(sb.requests[0].body), {firstName: "Jim"});
+
+        // Cleared field is removed.
+        form.submit({firstName: "John"}, null);
+        assert.deepStrictEqual(JSON.parse(sb.requests[1].body), {lastName: null});
+
+        form.submitMode = "json-patch";
+        form.submit({firstName: "Jim", lastName: "Doe", age: 30}, null);
+        assert.deepStrictEqual(JSON.parse(sb.requests[2].body), [
+            {op: "replace", path: "/firstName", value: "Jim"},
+            {op: "add", path: "/age", value: 30},
+        ]);
+    },
+
+    "submit keeps ETag for next update"() {
+        const sb = load();
+        const form = renderedForm(sb, {id: 1, firstName: "John"}, {firstName: "John"});
+
+        form.valueUrl = "/user/1.json";
+        form.etag = '"v1"';
+        form.submitUrl = "/user/1.json";
+        form.submitMethod = "PUT";
+        form.successStatus = 204;
+
+        form.submit({firstName: "Jim"}, form.etag);
+        assert.strictEqual(sb.requests[0].headers["If-Match"], '"v1"');
+        sb.requests[0].respond(204, "", {ETag: '"v2"'});
+        assert.strictEqual(form.etag, '"v2"');
+
+        // ETag is reloaded if submit response does not have it.
+        form.submit({firstName: "Jane"}, form.etag);
+        assert.strictEqual(sb.requests[1].headers["If-Match"], '"v2"');
+        sb.requests[1].respond(204);
+        assert.strictEqual(form.etag, '"v2"');
+        assert.strictEqual(sb.requests[2].method, "GET");
+        assert.strictEqual(sb.requests[2].url, "/user/1.json");
+        sb.requests[2].respond(200, '{"id":1,"firstName":"Jane"}', {ETag: '"v3"'});
+        assert.strictEqual(form.etag, '"v3"');
+    },
+
+    "draft is offered if it differs from form value"() {
+        const sb = load();
+        const form = renderedForm(sb, {id: 1, firstName: "John", bio: ""}, {firstName: "John"});
+        let shown = 0;
+
+        form.schemaName = "user";
+        form.autosave = true;
+        form.result = sb.$();
+        form.result.show = function () {
+            shown++;
+
+            return this;
+        };
+
+        form.saveDraft();
+        form.offerDraft();
+        assert.strictEqual(shown, 0);
+
+        sb.formValue = {firstName: "Jim"};
+        form.saveDraft();
+        form.offerDraft();
+        assert.strictEqual(shown, 1);
+
+        form.reset();
+        assert.deepStrictEqual(sb.storage, {});
+    },
+
+    "success callback is called before redirect"() {
+        const sb = load();
+        const form = renderedForm(sb, undefined, {firstName: "John"});
+        const calls = [];
+
+        form.submitUrl = "/users";
+        form.successStatus = 201;
+        form.successRedirect = "/user/{id}";
+        form.success = function (x) {
+            calls.push("success " + x.status + " " + sb.assigned);
+        };
+
+        form.submit({firstName: "John"}, null);
+        sb.requests[0].respond(201, '{"id":12}');
+
+        assert.deepStrictEqual(calls, ["success 201 null"]);
+        assert.strictEqual(sb.assigned, "http://localhost/user/12");
+    },
+
+    async "omit credentials mode uses fetch"() {
+        const sb = load();
+        const form = renderedForm(sb, undefined, {firstName: "John"});
+        let status = 0;
+
+        form.headers = {"X-Tenant-ID": "t1"};
+        form.credentials = "omit";
+        form.submitUrl = "/users";
+        form.successStatus = 200;
+        form.success = function (x) {
+            status = x.status;
+        };
+
+        form.submit({firstName: "John"}, null);
+        await new Promise((resolve) => setTimeout(resolve, 0));
+
+        assert.strictEqual(sb.requests.length, 0);
+        assert.strictEqual(sb.fetches[0].url, "/users");
+        assert.strictEqual(sb.fetches[0].init.method, "POST");
+        assert.strictEqual(sb.fetches[0].init.credentials, "omit");
+        assert.strictEqual(sb.fetches[0].init.headers["X-Tenant-ID"], "t1");
+        assert.deepStrictEqual(JSON.parse(sb.fetches[0].init.body), {firstName: "John"});
+        assert.strictEqual(status, 200);
+    },
+
+    "list requests have headers and credentials"() {
+        const sb = load();
+
+        sb.listToken = function (url, method) {
+            return {Authorization: "Bearer " + method};
+        };
+
+        const list = new sb.JSONList(sb.$(), {
+            listUrl: "/users.json",
+            headers: {"X-Tenant-ID": "t1"},
+            onHeaders: "listToken",
+            credentials: "include",
+        });
+
+        list.make();
+
+        assert.strictEqual(sb.requests[0].url, "/users.json");
+        assert.strictEqual(sb.requests[0].withCredentials, true);
+        assert.strictEqual(sb.requests[0].headers["X-Tenant-ID"], "t1");
+        assert.strictEqual(sb.requests[0].headers.Authorization, "Bearer GET");
+    },
+
+    "CSRF token is not sent to other origins"() {
+        const sb = load();
+        const form = renderedForm(sb, undefined, {firstName: "John"});
+
+        sb.meta["csrf-token"] = "secret";
+
+        form.submitUrl = "https://evil.example/collect";
+        form.submit({firstName: "John"}, null);
+        assert.strictEqual(sb.requests[0].headers["X-CSRF-Token"], undefined);
+
+        form.submitUrl = "/users";
+        form.submit({firstName: "John"}, null);
+        assert.strictEqual(sb.requests[1].headers["X-CSRF-Token"], "secret");
+    },
+
+    "headers are not sent to other origins"() {
+        const sb = load();
+        const form = renderedForm(sb, undefined, {firstName: "John"});
+
+        sb.JSONForm.setHeadersProvider(() => ({Authorization: "Bearer token"}));
+        form.headers = {"X-Tenant-ID": "t1"};
+        form.credentials = "include";
+
+        form.submitUrl = "https://evil.example/collect";
+        form.submit({firstName: "John"}, null);
+        assert.strictEqual(sb.requests[0].headers.Authorization, undefined);
+        assert.strictEqual(sb.requests[0].headers["X-Tenant-ID"], undefined);
+        assert.strictEqual(sb.requests[0].withCredentials, undefined);
+
+        form.submitUrl = "http://localhost/users";
+        form.submit({firstName: "John"}, null);
+        assert.strictEqual(sb.requests[1].headers.Authorization, "Bearer token");
+        assert.strictEqual(sb.requests[1].headers["X-Tenant-ID"], "t1");
+        assert.strictEqual(sb.requests[1].withCredentials, true);
+
+        sb.JSONForm.setTrustedOrigins(["https://api.example"]);
+        form.submitUrl = "https://api.example/users";
+        form.submit({firstName: "John"}, null);
+        assert.strictEqual(sb.requests[2].headers.Authorization, "Bearer token");
+    },
+};
+
+(async function () {
+    let failed = 0;
+
+    for (const name of Object.keys(tests)) {
+        try {
+            await tests[name]();
+            console.log("ok", name);
+        } catch (e) {
+            failed++;
+            console.log("FAIL", name);
+            console.log(e.stack);
+        }
+    }
+
+    process.exit(failed > 0 ? 1 : 0);
+})();
@@ -1,10 +1,33 @@
 package jsonform
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"io"
+	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// SubmitEncoding defines encoding of submitted form values.
+type SubmitEncoding string
+
+// Submit encodings.
+const (
+	// EncodingJSON submits JSON document, it is the default.
+	EncodingJSON = SubmitEncoding("json")
+
+	// EncodingFormURLEncoded submits application/x-www-form-urlencoded fields,
+	// field names follow FormItem.Key convention with array indexes, e.g. "user.firstName", "neighbors[0].age".
+	EncodingFormURLEncoded = SubmitEncoding("form-urlencoded")
+
+	// EncodingMultipart submits multipart/form-data fields named as in EncodingFormURLEncoded.
+	EncodingMultipart = SubmitEncoding("multipart")
 )
 
 // Form describes form parameters.
@@ -19,6 +42,25 @@ type Form struct {
 	SubmitMethod  string `json:"submitMethod,omitempty"`
 	SuccessStatus int    `json:"successStatus,omitempty"`
 
+	// SubmitMode defines whether whole value or only changes are submitted, default SubmitFull.
+	SubmitMode SubmitMode `json:"submitMode,omitempty"`
+
+	// SubmitEncoding defines encoding of submitted values, default EncodingJSON.
+	// Patches of SubmitMode are always submitted as JSON.
+	SubmitEncoding SubmitEncoding `json:"submitEncoding,omitempty"`
+
+	// SuccessRedirect is a URL to navigate to after successful submit, OnSuccess is called before navigation.
+	// URL can have {name} placeholders for fields of response JSON, e.g. "/user/{id}" or "/user/{user.id}".
+	SuccessRedirect string `json:"successRedirect,omitempty"`
+
+	// Autosave enables keeping drafts of form values in browser localStorage,
+	// drafts are keyed by SchemaName and ValueURL and can be restored when form is opened again.
+	Autosave bool `json:"autosave,omitempty"`
+
+	// URLParams are values for {name} placeholders in ValueURL and SubmitURL, see ExpandURL.
+	// URLs are used as is if URLParams is nil.
+	URLParams map[string]string `json:"-"`
+
 	// OnSuccess is a javascript callback that receives XMLHttpRequest value in case of successful response.
 	OnSuccess template.JS `json:"-"`
 	// OnFail is a javascript callback that receives XMLHttpRequest value in case of a failure response.
@@ -29,6 +71,16 @@ type Form struct {
 	OnBeforeSubmit template.JS `json:"-"`
 	// OnRequestFinished is a javascript callback that receives XMLHttpRequest after request is finished.
 	OnRequestFinished template.JS `json:"-"`
+	// OnHeaders is a javascript callback that receives URL and method of request and returns an object with
+	// additional request headers, e.g. authorization token.
+	OnHeaders template.JS `json:"-"`
+
+	// Headers are added to schema, value and submit requests.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Credentials is a credentials mode of requests, "include" sends cookies and HTTP authentication
+	// with cross-origin requests, "omit" sends requests without them, default "same-origin".
+	Credentials string `json:"credentials,omitempty"`
 
 	Schema *FormSchema `json:"schema,omitempty"`
 	Value  interface{} `json:"value,omitempty"`
@@ -55,74 +107,317 @@ type Page struct {
 
 	// Title is set to HTML document title.
 	Title string
+
+	// BaseURL is a URL prefix of static assets, default is the prefix of mounted repository.
+	BaseURL string
+
+	// CSRFToken is an anti-forgery token that form.js sends with submit requests, see CSRF.Token.
+	CSRFToken string
+
+	// CSRFHeader is a name of request header for CSRFToken, default CSRFHeader.
+	CSRFHeader string
+
+	// CSRFCookie is a name of cookie that form.js reads token from when CSRFToken is empty,
+	// it should be set if CookieCSRFStorage.CookieName is customized, default CSRFCookie.
+	CSRFCookie string
+
+	// Nonce is added to every script tag to comply with Content-Security-Policy.
+	Nonce string
+
+	// StrictCSP enables rendering without inline scripts.
+	//
+	// Content-Security-Policy still needs script-src 'unsafe-eval', because bundled jsonform.js
+	// compiles templates with underscore _.template (new Function).
+	//
+	// Form parameters are passed as JSON in data attribute and form callbacks (OnSuccess, etc.)
+	// must be names of functions registered with JSONForm.registerCallback or global functions.
+	StrictCSP bool
+}
+
+// formData is a form prepared for rendering.
+type formData struct {
+	Form
+
+	// Params is a JSON representation of form parameters for StrictCSP mode.
+	Params string
+}
+
+// strictParams exposes callbacks as function names.
+type strictParams struct {
+	Form
+
+	OnSuccess         string `json:"onSuccess,omitempty"`
+	OnFail            string `json:"onFail,omitempty"`
+	OnError           string `json:"onError,omitempty"`
+	OnBeforeSubmit    string `json:"onBeforeSubmit,omitempty"`
+	OnRequestFinished string `json:"onRequestFinished,omitempty"`
+	OnHeaders         string `json:"onHeaders,omitempty"`
+}
+
+var callbackName = regexp.MustCompile(`^[A-Za-z_$][\w$]*(\.[A-Za-z_$][\w$]*)*$`)
+
+func (f Form) strictParams() (string, error) {
+	p := strictParams{
+		Form:              f,
+		OnSuccess:         string(f.OnSuccess),
+		OnFail:            string(f.OnFail),
+		OnError:           string(f.OnError),
+		OnBeforeSubmit:    string(f.OnBeforeSubmit),
+		OnRequestFinished: string(f.OnRequestFinished),
+		OnHeaders:         string(f.OnHeaders),
+	}
+
+	for _, cb := range []string{p.OnSuccess, p.OnFail, p.OnError, p.OnBeforeSubmit, p.OnRequestFinished, p.OnHeaders} {
+		if cb != "" && !callbackName.MatchString(cb) {
+			return "", fmt.Errorf("form %s: callback must be a function name in strict CSP mode: %q", f.Name, cb)
+		}
+	}
+
+	j, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+
+	return string(j), nil
 }
 
 var formTemplate = loadTemplate("form_tmpl.html")
 
 // Render renders forms as web page.
+//
+// Repository.Authorize is not checked, field policies are applied with context.Background(),
+// as for an anonymous caller, use RenderContext with request context to render forms for a user.
 func (r *Repository) Render(w io.Writer, p Page, forms ...Form) error {
-	type pageData struct {
-		Page
-		Params  []Form
-		BaseURL string
+	return r.RenderContext(serverSide(), w, p, forms...)
+}
+
+// RenderContext renders forms as web page with field policies applied in context, see Repository.SchemaFor.
+//
+// Fields removed by policies are also removed from Form.Value, see Repository.ValueFor.
+func (r *Repository) RenderContext(ctx context.Context, w io.Writer, p Page, forms ...Form) error {
+	d, err := r.pageData(ctx, p, forms, "")
+	if err != nil {
+		return err
+	}
+
+	return formTemplate.Execute(w, d)
+}
+
+// RenderFragment renders form containers with init script to embed in a page of html/template layout.
+//
+// Page.AppendHTMLHead, Page.PrependHTML, Page.AppendHTML and Page.Title are ignored,
+// layout should include Repository.Assets once in the <head> of a page.
+// Forms without name are named uniquely within repository, so that multiple fragments can share a page.
+// Authorize is not checked, field policies are applied with context.Background(), see RenderFragmentContext.
+func (r *Repository) RenderFragment(p Page, forms ...Form) (template.HTML, error) {
+	return r.RenderFragmentContext(serverSide(), p, forms...)
+}
+
+// RenderFragmentContext renders form fragment with field policies applied in context, see RenderFragment.
+func (r *Repository) RenderFragmentContext(ctx context.Context, p Page, forms ...Form) (template.HTML, error) {
+	prefix := "f" + strconv.FormatUint(uint64(atomic.AddUint32(&r.fragments, 1)), 10) + "-"
+
+	d, err := r.pageData(ctx, p, forms, prefix)
+	if err != nil {
+		return "", err
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	if err := formTemplate.ExecuteTemplate(buf, "fragment", d); err != nil {
+		return "", err
+	}
+
+	return template.HTML(buf.String()), nil
+}
+
+// Assets returns stylesheets and scripts for forms of RenderFragment to include once in the <head> of a page.
+//
+// Page.BaseURL, Page.Nonce and Page.CSRFToken are applied to tags.
+func (r *Repository) Assets(p Page) (template.HTML, error) {
+	d, err := r.pageData(context.Background(), p, nil, "")
+	if err != nil {
+		return "", err
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	if err := formTemplate.ExecuteTemplate(buf, "assets", d); err != nil {
+		return "", err
 	}
 
+	return template.HTML(buf.String()), nil
+}
+
+type pageData struct {
+	Page
+	Params  []formData
+	BaseURL string
+}
+
+// pageData prepares forms for rendering, forms without name are named with prefix and position.
+func (r *Repository) pageData(ctx context.Context, p Page, forms []Form, namePrefix string) (pageData, error) {
 	d := pageData{
 		Page:    p,
 		BaseURL: r.baseURL,
 	}
 
+	if p.BaseURL != "" {
+		d.BaseURL = p.BaseURL
+	}
+
+	if (d.CSRFToken != "" || d.CSRFCookie != "") && d.CSRFHeader == "" {
+		d.CSRFHeader = CSRFHeader
+	}
+
 	for i, form := range forms {
 		if d.Title == "" {
 			d.Title = form.Title
 		}
 
 		if form.Name == "" {
-			form.Name = strconv.Itoa(i)
+			form.Name = namePrefix + strconv.Itoa(i)
+		}
+
+		if err := checkCredentials(form.Credentials); err != nil {
+			return d, fmt.Errorf("form %s: %w", form.Name, err)
+		}
+
+		if form.URLParams != nil {
+			var err error
+
+			if form.ValueURL, err = ExpandURL(form.ValueURL, form.URLParams); err != nil {
+				return d, fmt.Errorf("form %s: %w", form.Name, err)
+			}
+
+			if form.SubmitURL, err = ExpandURL(form.SubmitURL, form.URLParams); err != nil {
+				return d, fmt.Errorf("form %s: %w", form.Name, err)
+			}
 		}
 
 		if form.Schema == nil && form.Value != nil {
-			s, err := r.formSchema(form.Value)
+			s, err := r.formSchema(ctx, form.Value)
 			if err != nil {
-				return err
+				return d, err
 			}
 
-			form.Schema = &FormSchema{}
-			*form.Schema = *s
-
-			submit := FormItem{FormType: "submit", FormTitle: "Submit"}
+			form.Schema = s.WithSubmit(form.SubmitText)
 
 			if form.OnBeforeSubmit == "" && form.OnRequestFinished == "" {
 				form.OnBeforeSubmit = "startSpinner"
 				form.OnRequestFinished = "stopSpinner"
 			}
+		}
+
+		if form.Value != nil {
+			name := form.SchemaName
+			if name == "" && r.Schema(form.Value) != nil {
+				name = r.Name(form.Value)
+			}
+
+			if name != "" && r.SchemaByName(name) != nil {
+				v, err := r.ValueFor(ctx, name, form.Value)
+				if err != nil {
+					return d, err
+				}
+
+				form.Value = v
+			}
+		}
 
-			if form.SubmitText != "" {
-				submit.FormTitle = form.SubmitText
+		fd := formData{Form: form}
+
+		if p.StrictCSP {
+			params, err := form.strictParams()
+			if err != nil {
+				return d, err
 			}
 
-			form.Schema.Form = append(form.Schema.Form, submit)
+			fd.Params = params
 		}
 
-		d.Params = append(d.Params, form)
+		d.Params = append(d.Params, fd)
 	}
 
-	return formTemplate.Execute(w, d)
+	return d, nil
+}
+
+// checkCredentials validates credentials mode of requests.
+func checkCredentials(mode string) error {
+	switch mode {
+	case "", "same-origin", "include", "omit":
+		return nil
+	default:
+		return fmt.Errorf("unsupported credentials mode %q, same-origin, include or omit expected", mode)
+	}
+}
+
+// WithSubmit returns a copy of form schema with submit button, default submit text is "Submit".
+func (s *FormSchema) WithSubmit(submitText string) *FormSchema {
+	submit := FormItem{FormType: "submit", FormTitle: "Submit"}
+
+	if submitText != "" {
+		submit.FormTitle = submitText
+	}
+
+	fs := &FormSchema{Schema: s.Schema}
+	fs.Form = append(append(make([]FormItem, 0, len(s.Form)+1), s.Form...), submit)
+
+	return fs
+}
+
+func (r *Repository) formSchema(ctx context.Context, value interface{}) (*FormSchema, error) {
+	if err := r.ensureSchema(value); err != nil {
+		return nil, err
+	}
+
+	return r.SchemaFor(ctx, r.Name(value))
 }
 
-func (r *Repository) formSchema(value interface{}) (*FormSchema, error) {
-	formSchema := r.Schema(value)
-	if formSchema == nil {
-		if r.Strict {
-			return nil, fmt.Errorf("missing form schema for %T", value)
+// ensureSchema registers schema of value if it is missing and repository is not strict,
+// authorization and field policies are not applied.
+func (r *Repository) ensureSchema(value interface{}) error {
+	if r.Schema(value) != nil {
+		return nil
+	}
+
+	if r.Strict {
+		return fmt.Errorf("missing form schema for %T", value)
+	}
+
+	return r.Add(value)
+}
+
+// ExpandURL replaces {name} placeholders in URL template with escaped values of params,
+// for example "/user/{id}.json" or "/users?search={query}".
+//
+// Values are path-escaped before "?" and query-escaped after it,
+// placeholders may have route patterns that are ignored, e.g. "{id:[0-9]+}".
+func ExpandURL(tmpl string, params map[string]string) (string, error) {
+	query := strings.IndexAny(tmpl, "?#")
+	res := strings.Builder{}
+	last := 0
+
+	for _, m := range pathParam.FindAllStringSubmatchIndex(tmpl, -1) {
+		name := tmpl[m[2]:m[3]]
+
+		v, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("missing URL parameter %s in %s", name, tmpl)
 		}
 
-		if err := r.Add(value); err != nil {
-			return nil, err
+		res.WriteString(tmpl[last:m[0]])
+
+		if query != -1 && m[0] > query {
+			res.WriteString(url.QueryEscape(v))
+		} else {
+			res.WriteString(url.PathEscape(v))
 		}
 
-		formSchema = r.Schema(value)
+		last = m[1]
 	}
 
-	return formSchema, nil
+	res.WriteString(tmpl[last:])
+
+	return res.String(), nil
 }
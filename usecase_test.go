@@ -0,0 +1,201 @@
+package jsonform_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/jsonschema-go"
+)
+
+func TestRepository_Render_strictCSP(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.Render(buf, jsonform.Page{Nonce: "abc123", StrictCSP: true}, jsonform.Form{
+		Title:     "Create User",
+		SubmitURL: "/users",
+		Value:     User{},
+		OnSuccess: "app.userCreated",
+	}))
+
+	html := buf.String()
+	assert.Contains(t, html, `<script type="text/javascript" src="form.js" nonce="abc123"></script>`)
+	assert.Contains(t, html, `data-jsonform="{&#34;name&#34;:&#34;0&#34;,&#34;title&#34;:&#34;Create User&#34;`)
+	assert.Contains(t, html, `&#34;onSuccess&#34;:&#34;app.userCreated&#34;,&#34;onBeforeSubmit&#34;:&#34;startSpinner&#34;`)
+	assert.NotContains(t, html, "form.make(params)")
+
+	err := repo.Render(buf, jsonform.Page{StrictCSP: true}, jsonform.Form{
+		Value:     User{},
+		OnSuccess: "function(x){alert(x.status)}",
+	})
+	assert.EqualError(t, err, `form 0: callback must be a function name in strict CSP mode: "function(x){alert(x.status)}"`)
+}
+
+func TestRepository_Render_nonce(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.Render(buf, jsonform.Page{Nonce: "abc123"}, jsonform.Form{
+		SubmitURL: "/users",
+		Value:     User{},
+	}))
+
+	html := buf.String()
+	assert.Contains(t, html, `<script type="text/javascript" nonce="abc123">`)
+	assert.Contains(t, html, "form.make(params)")
+	assert.NotContains(t, html, "data-jsonform=")
+}
+
+func TestExpandURL(t *testing.T) {
+	params := map[string]string{"id": "a/b c", "q": "x&y=z c"}
+
+	for tmpl, expected := range map[string]string{
+		"/user/{id}.json":              "/user/a%2Fb%20c.json",
+		"/user/{id:[a-z]+}":            "/user/a%2Fb%20c",
+		"/users?search={q}&id={id}":    "/users?search=x%26y%3Dz+c&id=a%2Fb+c",
+		"/static":                      "/static",
+		"https://example.com/{id}#{q}": "https://example.com/a%2Fb%20c#x%26y%3Dz+c",
+	} {
+		u, err := jsonform.ExpandURL(tmpl, params)
+		require.NoError(t, err)
+		assert.Equal(t, expected, u, tmpl)
+	}
+
+	_, err := jsonform.ExpandURL("/user/{uid}.json", params)
+	assert.EqualError(t, err, "missing URL parameter uid in /user/{uid}.json")
+}
+
+func TestRepository_Render_urlParams(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.Render(buf, jsonform.Page{StrictCSP: true}, jsonform.Form{
+		ValueURL:  "/user/{id}.json",
+		SubmitURL: "/user/{id}.json?notify={notify}",
+		URLParams: map[string]string{"id": "12", "notify": "a&b"},
+		Value:     User{},
+	}))
+
+	assert.Contains(t, buf.String(), `&#34;valueUrl&#34;:&#34;/user/12.json&#34;`)
+	assert.Contains(t, buf.String(), `&#34;submitUrl&#34;:&#34;/user/12.json?notify=a%26b&#34;`)
+
+	assert.EqualError(t, repo.Render(buf, jsonform.Page{}, jsonform.Form{
+		SubmitURL: "/user/{id}.json",
+		URLParams: map[string]string{},
+		Value:     User{},
+	}), "form 0: missing URL parameter id in /user/{id}.json")
+}
+
+func TestRepository_Render_submitMode(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.Render(buf, jsonform.Page{StrictCSP: true}, jsonform.Form{
+		SubmitURL:    "/user/1.json",
+		SubmitMethod: "PATCH",
+		SubmitMode:   jsonform.SubmitMergePatch,
+		Value:        User{},
+	}))
+
+	assert.Contains(t, buf.String(), `&#34;submitMode&#34;:&#34;merge-patch&#34;`)
+}
+
+func TestRepository_Render_autosave(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.Render(buf, jsonform.Page{StrictCSP: true}, jsonform.Form{
+		SubmitURL: "/users",
+		Value:     User{},
+		Autosave:  true,
+	}))
+
+	assert.Contains(t, buf.String(), `&#34;autosave&#34;:true`)
+}
+
+func TestRepository_Render_successRedirect(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.Render(buf, jsonform.Page{StrictCSP: true}, jsonform.Form{
+		SubmitURL:       "/users",
+		Value:           User{},
+		SuccessRedirect: "/user/{id}",
+		URLParams:       map[string]string{},
+	}))
+
+	// Placeholders of SuccessRedirect are filled from response on client side.
+	assert.Contains(t, buf.String(), `&#34;successRedirect&#34;:&#34;/user/{id}&#34;`)
+}
+
+func TestRepository_Render_submitEncoding(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.Render(buf, jsonform.Page{StrictCSP: true}, jsonform.Form{
+		SubmitURL:      "/users",
+		Value:          User{},
+		SubmitEncoding: jsonform.EncodingFormURLEncoded,
+	}))
+
+	assert.Contains(t, buf.String(), `&#34;submitEncoding&#34;:&#34;form-urlencoded&#34;`)
+}
+
+func TestRepository_Render_requestHeaders(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.Render(buf, jsonform.Page{StrictCSP: true}, jsonform.Form{
+		SubmitURL:   "/users",
+		Value:       User{},
+		Headers:     map[string]string{"X-Tenant": "acme"},
+		OnHeaders:   "auth.headers",
+		Credentials: "include",
+	}))
+
+	assert.Contains(t, buf.String(), `&#34;headers&#34;:{&#34;X-Tenant&#34;:&#34;acme&#34;}`)
+	assert.Contains(t, buf.String(), `&#34;onHeaders&#34;:&#34;auth.headers&#34;`)
+	assert.Contains(t, buf.String(), `&#34;credentials&#34;:&#34;include&#34;`)
+
+	assert.Error(t, repo.Render(buf, jsonform.Page{StrictCSP: true}, jsonform.Form{
+		Value:     User{},
+		OnHeaders: "function(){return {}}",
+	}))
+}
+
+func TestRepository_RenderFragment(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	assets, err := repo.Assets(jsonform.Page{BaseURL: "/json-form/", Nonce: "abc123", CSRFToken: "tkn"})
+	require.NoError(t, err)
+	assert.Contains(t, string(assets), `<script type="text/javascript" src="/json-form/form.js" nonce="abc123"></script>`)
+	assert.Contains(t, string(assets), `<meta name="csrf-token" content="tkn">`)
+	assert.NotContains(t, string(assets), "<html>")
+
+	fragment, err := repo.RenderFragment(jsonform.Page{Nonce: "abc123"}, jsonform.Form{
+		Title:     "Create User",
+		SubmitURL: "/users",
+		Value:     User{},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(fragment), `<form id="schema-form-f1-0" class="pure-form"></form>`)
+	assert.Contains(t, string(fragment), `<script type="text/javascript" nonce="abc123">`)
+	assert.Contains(t, string(fragment), `"submitUrl":"/users"`)
+	assert.NotContains(t, string(fragment), "<head>")
+	assert.NotContains(t, string(fragment), "jquery")
+
+	fragment, err = repo.RenderFragment(jsonform.Page{StrictCSP: true}, jsonform.Form{Name: "user", Value: User{}})
+	require.NoError(t, err)
+	assert.Contains(t, string(fragment), `<form id="schema-form-user" class="pure-form"></form>`)
+	assert.Contains(t, string(fragment), `data-jsonform="{&#34;name&#34;:&#34;user&#34;`)
+	assert.NotContains(t, string(fragment), "<script")
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.Render(buf, jsonform.Page{}, jsonform.Form{Value: User{}}))
+	assert.True(t, strings.HasPrefix(buf.String(), "<!DOCTYPE html>"))
+	assert.True(t, strings.HasSuffix(buf.String(), "</html>"))
+}
@@ -0,0 +1,211 @@
+package jsonform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+)
+
+// viewField is a titled value of read-only view.
+type viewField struct {
+	Title string
+
+	// Value is a formatted scalar value.
+	Value string
+
+	// Fields are nested fields of titled section.
+	Fields []viewField
+
+	// Items are array items with their fields, Items is not nil for arrays.
+	Items []viewItem
+}
+
+// viewItem is an item of array.
+type viewItem struct {
+	Title  string
+	Fields []viewField
+}
+
+var viewTemplate = loadTemplate("view_tmpl.html")
+
+// RenderView renders value as read-only web page with titles from its form schema.
+//
+// Page has no inputs and no scripts, enum values are shown with titles of FormItem.TitleMap.
+// Repository.Authorize is not checked, field policies are applied as for an anonymous caller.
+func (r *Repository) RenderView(w io.Writer, p Page, value interface{}) error {
+	return r.RenderViewContext(serverSide(), w, p, value)
+}
+
+// RenderViewContext renders value as read-only web page with field policies applied in context,
+// hidden and removed fields are not shown, see Repository.SchemaFor.
+func (r *Repository) RenderViewContext(ctx context.Context, w io.Writer, p Page, value interface{}) error {
+	fs, err := r.formSchema(ctx, value)
+	if err != nil {
+		return err
+	}
+
+	doc, err := jsonDocument(value)
+	if err != nil {
+		return err
+	}
+
+	d := struct {
+		Page
+		Description string
+		Fields      []viewField
+		BaseURL     string
+	}{
+		Page:    p,
+		Fields:  viewFields(&fs.Schema, fs.Form, doc, nil),
+		BaseURL: r.baseURL,
+	}
+
+	if p.BaseURL != "" {
+		d.BaseURL = p.BaseURL
+	}
+
+	if d.Title == "" && fs.Schema.Title != nil {
+		d.Title = *fs.Schema.Title
+	}
+
+	if fs.Schema.Description != nil {
+		d.Description = *fs.Schema.Description
+	}
+
+	return viewTemplate.Execute(w, d)
+}
+
+// viewFields prepares fields of form items, index holds positions of enclosing array items.
+func viewFields(schema *jsonschema.Schema, items []FormItem, doc interface{}, index []int) []viewField {
+	var fields []viewField
+
+	for _, fi := range items {
+		switch {
+		case fi.FormType == "hidden" || fi.FormType == "submit" || fi.FormType == "button" ||
+			fi.FormType == "actions" || fi.FormType == "help":
+			continue
+		case fi.Key == "":
+			nested := viewFields(schema, fi.Items, doc, index)
+
+			if fi.FormTitle == "" {
+				fields = append(fields, nested...)
+			} else if len(nested) > 0 {
+				fields = append(fields, viewField{Title: fi.FormTitle, Fields: nested})
+			}
+
+			continue
+		}
+
+		f := viewField{Title: itemTitle(schema, fi)}
+		v, _ := valueAtIndex(doc, fi.Key, index)
+
+		if a, ok := v.([]interface{}); ok && fi.FormType == "array" {
+			f.Items = make([]viewItem, 0, len(a))
+
+			for i := range a {
+				f.Items = append(f.Items, viewItem{
+					Title:  fmt.Sprintf("#%d", i+1),
+					Fields: viewFields(schema, fi.Items, doc, append(index[:len(index):len(index)], i)),
+				})
+			}
+		} else {
+			f.Value = formatValue(v, fi.TitleMap)
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+// valueAtIndex returns value of generic JSON document by form item key,
+// array items ("[]") are taken at positions of index.
+func valueAtIndex(doc interface{}, key string, index []int) (interface{}, bool) {
+	v := doc
+
+	for _, part := range strings.Split(key, ".") {
+		name, arrays := keyPart(part)
+
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		if v, ok = m[name]; !ok {
+			return nil, false
+		}
+
+		for ; arrays > 0; arrays-- {
+			a, ok := v.([]interface{})
+			if !ok || len(index) == 0 || index[0] >= len(a) {
+				return nil, false
+			}
+
+			v = a[index[0]]
+			index = index[1:]
+		}
+	}
+
+	return v, true
+}
+
+// formatValue formats scalar value, or list of scalars, for display.
+func formatValue(v interface{}, titleMap map[string]string) string {
+	switch vv := v.(type) {
+	case nil:
+		return ""
+	case string:
+		if t, ok := titleMap[vv]; ok {
+			return t
+		}
+
+		return vv
+	case json.Number:
+		if t, ok := titleMap[vv.String()]; ok {
+			return t
+		}
+
+		return vv.String()
+	case bool:
+		if t, ok := titleMap[fmt.Sprint(vv)]; ok {
+			return t
+		}
+
+		if vv {
+			return "Yes"
+		}
+
+		return "No"
+	case []interface{}:
+		values := make([]string, 0, len(vv))
+
+		for _, item := range vv {
+			values = append(values, formatValue(item, titleMap))
+		}
+
+		return strings.Join(values, ", ")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(vv))
+
+		for k := range vv {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		values := make([]string, 0, len(vv))
+
+		for _, k := range keys {
+			values = append(values, k+": "+formatValue(vv[k], nil))
+		}
+
+		return strings.Join(values, ", ")
+	default:
+		return fmt.Sprint(vv)
+	}
+}
@@ -0,0 +1,77 @@
+package jsonform_test
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/jsonform-go"
+	"github.com/swaggest/jsonschema-go"
+)
+
+var spaces = regexp.MustCompile(`\s+`)
+
+func TestRepository_RenderView(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.RenderView(buf, jsonform.Page{}, UserWithNeighbors{
+		User: User{FirstName: "John", LastName: "Doe", Age: 30, Status: "active"},
+		Neighbors: []User{
+			{FirstName: "Jane", LastName: "Roe", Locale: "en-US"},
+		},
+	}))
+
+	html := spaces.ReplaceAllString(buf.String(), " ")
+	assert.NotContains(t, html, "<script")
+	assert.NotContains(t, html, "<input")
+	assert.Contains(t, html, `<dt>First name</dt> <dd> John </dd>`)
+	assert.Contains(t, html, `<dt>Age</dt> <dd> 30 </dd>`)
+	assert.Contains(t, html, `<dt>Bio</dt> <dd> <span class="muted">&mdash;</span> </dd>`)
+	assert.Contains(t, html, `<dt>Neighbors</dt> <dd> <div class="jsonform-view-item"> <strong>#1</strong>`+
+		` <dl class="dl-horizontal jsonform-view"> <dt>First name</dt> <dd> Jane </dd>`)
+	assert.Contains(t, html, `<dt>User locale</dt> <dd> en-US </dd>`)
+}
+
+type ticket struct {
+	Status   string   `json:"status"`
+	Priority int      `json:"priority"`
+	Tags     []string `json:"tags"`
+	Secret   string   `json:"secret"`
+}
+
+func TestRepository_RenderViewContext(t *testing.T) {
+	repo := jsonform.NewRepository(&jsonschema.Reflector{})
+	require.NoError(t, repo.AddSchemaJSON(repo.Name(ticket{}), []byte(`{
+	  "type":"object","title":"Ticket",
+	  "properties":{
+		"status":{"type":"string","title":"Status","x-jsonform-titleMap":{"open":"Open","closed":"Closed"}},
+		"priority":{"type":"integer","title":"Priority","x-jsonform-titleMap":{"1":"High"}},
+		"tags":{"type":"array","title":"Tags","items":{"type":"string"}},
+		"secret":{"type":"string","title":"Secret"}
+	  }
+	}`)))
+
+	repo.AddPolicy(func(ctx context.Context, schemaName string, item jsonform.FormItem) jsonform.FieldAccess {
+		if item.Key == "secret" {
+			return jsonform.FieldHidden
+		}
+
+		return jsonform.FieldEditable
+	})
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, repo.RenderViewContext(context.Background(), buf, jsonform.Page{}, ticket{
+		Status: "open", Priority: 1, Tags: []string{"a", "b"}, Secret: "s3cr3t",
+	}))
+
+	html := spaces.ReplaceAllString(buf.String(), " ")
+	assert.Contains(t, html, `<title>Ticket</title>`)
+	assert.Contains(t, html, `<dt>Status</dt> <dd> Open </dd>`)
+	assert.Contains(t, html, `<dt>Priority</dt> <dd> High </dd>`)
+	assert.Contains(t, html, `<dt>Tags</dt> <dd> a, b </dd>`)
+	assert.NotContains(t, html, "s3cr3t")
+}